@@ -0,0 +1,477 @@
+package taps
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_TIME_FORMAT = "2006/01/02"
+	TAG_NAME            = "taps"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ---------------------------------------------
+// Load / Save
+// ---------------------------------------------
+// Load stores the struct fields tagged `taps:"name"` to the panel.
+// Slices are stored to list fields, and [][]T to grid fields as [row][col].
+// A grid is tagged with the Name of the grid, and T is a struct tagged
+// with the names of GridFields, or a value if the grid has one GridField.
+func (p *Panel) Load(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("taps: Load: %T is not a struct", v)
+	}
+	return p.loadStruct(rv, nil)
+}
+
+// Save gets the data of the panel into the struct fields tagged `taps:"name"`.
+func (p *Panel) Save(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("taps: Save: %T is not a pointer to struct", v)
+	}
+	return p.saveStruct(rv.Elem(), nil)
+}
+
+// gridCell is the cell of the grid which a struct is bound to.
+type gridCell struct {
+	grid     string
+	col, row int
+}
+
+// boundName returns the name of the field tagged name; the name of the
+// cell if the struct is a cell of the grid.
+func (p *Panel) boundName(name string, cell *gridCell) string {
+	if cell == nil {
+		return name
+	}
+	return p.GetGridFieldName(name, cell.col, cell.row)
+}
+
+func (p *Panel) loadStruct(rv reflect.Value, cell *gridCell) error {
+	rt := rv.Type()
+	for k := 0; k < rt.NumField(); k++ {
+		sf := rt.Field(k)
+		name := sf.Tag.Get(TAG_NAME)
+		if sf.Anonymous && name == "" && rv.Field(k).Kind() == reflect.Struct {
+			if err := p.loadStruct(rv.Field(k), cell); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+		if cell != nil && !p.isGridColumn(cell.grid, name) {
+			return fmt.Errorf("taps: %s: no grid field %s", cell.grid, name)
+		}
+		if err := p.loadValue(p.boundName(name, cell), rv.Field(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Panel) saveStruct(rv reflect.Value, cell *gridCell) error {
+	rt := rv.Type()
+	for k := 0; k < rt.NumField(); k++ {
+		sf := rt.Field(k)
+		name := sf.Tag.Get(TAG_NAME)
+		if sf.Anonymous && name == "" && rv.Field(k).Kind() == reflect.Struct {
+			if err := p.saveStruct(rv.Field(k), cell); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+		if cell != nil && !p.isGridColumn(cell.grid, name) {
+			return fmt.Errorf("taps: %s: no grid field %s", cell.grid, name)
+		}
+		if err := p.saveValue(p.boundName(name, cell), rv.Field(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isGridValue(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Slice && v.Type().Elem().Elem().Kind() != reflect.Uint8
+}
+
+func isListValue(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !isGridValue(v)
+}
+
+func (p *Panel) loadValue(name string, v reflect.Value) error {
+	if isGridValue(v) {
+		cols, rows, err := p.getBoundGridSize(name, v.Type().Elem().Elem())
+		if err != nil {
+			return err
+		}
+		for row := 0; row < v.Len(); row++ {
+			for col := 0; col < v.Index(row).Len(); col++ {
+				if col >= cols || row >= rows {
+					return fmt.Errorf("taps: %s: no grid cell (%d, %d)", name, col, row)
+				}
+				if err := p.loadCell(name, col, row, v.Index(row).Index(col)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if isListValue(v) {
+		f := p.getFirstList(name)
+		if f == nil {
+			return fmt.Errorf("taps: %s: not a list field", name)
+		}
+		var listData []string
+		for k := 0; k < v.Len(); k++ {
+			s, err := f.formatValue(v.Index(k))
+			if err != nil {
+				return err
+			}
+			if err := f.checkValue(s); err != nil {
+				return err
+			}
+			listData = append(listData, s)
+		}
+		p.StoreList(listData, name)
+		return nil
+	}
+
+	f := p.GetDataField(name)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", name)
+	}
+	s, err := f.formatValue(v)
+	if err != nil {
+		return err
+	}
+	return p.storeValue(s, name)
+}
+
+func (p *Panel) saveValue(name string, v reflect.Value) error {
+	if isGridValue(v) {
+		cols, rows, err := p.getBoundGridSize(name, v.Type().Elem().Elem())
+		if err != nil {
+			return err
+		}
+		grid := reflect.MakeSlice(v.Type(), rows, rows)
+		for row := 0; row < rows; row++ {
+			grid.Index(row).Set(reflect.MakeSlice(v.Type().Elem(), cols, cols))
+			for col := 0; col < cols; col++ {
+				if err := p.saveCell(name, col, row, grid.Index(row).Index(col)); err != nil {
+					return err
+				}
+			}
+		}
+		v.Set(grid)
+		return nil
+	}
+
+	if isListValue(v) {
+		f := p.getFirstList(name)
+		if f == nil {
+			return fmt.Errorf("taps: %s: not a list field", name)
+		}
+		listData := p.GetList(name)
+		list := reflect.MakeSlice(v.Type(), len(listData), len(listData))
+		for k, s := range listData {
+			if err := f.parseValue(s, list.Index(k)); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+		return nil
+	}
+
+	f := p.GetDataField(name)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", name)
+	}
+	return f.parseValue(f.getValue(v), v)
+}
+
+// ---------------------------------------------
+// Grid
+// ---------------------------------------------
+// getBoundGridSize returns the number of cols and rows of the grid n,
+// which is bound to [][]t.
+func (p *Panel) getBoundGridSize(n string, t reflect.Type) (int, int, error) {
+	columns := p.getGridColumns(n)
+	if len(columns) == 0 {
+		return 0, 0, fmt.Errorf("taps: %s: not a grid field", n)
+	}
+	if !isCellStruct(t) && len(columns) != 1 {
+		return 0, 0, fmt.Errorf("taps: %s: grid with several GridFields needs a struct, not %s", n, t)
+	}
+	cols, rows := p.getGridSize(columns[0])
+	return cols, rows, nil
+}
+
+// isCellStruct reports whether the cell of the grid is bound to a struct.
+func isCellStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+func (p *Panel) isGridColumn(grid, name string) bool {
+	for _, x := range p.getGridColumns(grid) {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Panel) loadCell(grid string, col, row int, v reflect.Value) error {
+	if !isCellStruct(v.Type()) {
+		return p.loadValue(p.GetGridFieldName(p.getGridColumns(grid)[0], col, row), v)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return p.loadStruct(v, &gridCell{grid: grid, col: col, row: row})
+}
+
+func (p *Panel) saveCell(grid string, col, row int, v reflect.Value) error {
+	if !isCellStruct(v.Type()) {
+		n := p.GetGridFieldName(p.getGridColumns(grid)[0], col, row)
+		if p.GetDataField(n) == nil && p.getFirstList(n) == nil {
+			return nil
+		}
+		return p.saveValue(n, v)
+	}
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	return p.saveStruct(v, &gridCell{grid: grid, col: col, row: row})
+}
+
+// getGridSize returns the number of cols and rows of the grid field n.
+func (p *Panel) getGridSize(n string) (int, int) {
+	name := n + GRID_SEP
+	cols, rows := 0, 0
+	for _, f := range p.Field {
+		if !strings.HasPrefix(f.Name, name) || len(f.Name) < len(name)+7 {
+			continue
+		}
+		col, err1 := strconv.Atoi(f.Name[len(name) : len(name)+3])
+		row, err2 := strconv.Atoi(f.Name[len(name)+4 : len(name)+7])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if cols < col+1 {
+			cols = col + 1
+		}
+		if rows < row+1 {
+			rows = row + 1
+		}
+	}
+	return cols, rows
+}
+
+// getValue returns the data for v; the raw data for numbers.
+func (f *DataField) getValue(v reflect.Value) string {
+	t := v.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return f.getNumber()
+	}
+	return string(f.RData)
+}
+
+// getNumber returns the data without the literals of Picture, except
+// the decimal point.
+func (f *DataField) getNumber() string {
+	if len(f.Picture) == 0 {
+		return string(f.RData)
+	}
+	pic := []rune(f.Picture)
+	point := f.getDecimalPoint()
+	var raw []rune
+	for i, r := range f.RData {
+		if i < len(pic) && (isPictureSlot(pic[i]) || i == point) {
+			raw = append(raw, r)
+		}
+	}
+	return string(raw)
+}
+
+// ---------------------------------------------
+// Convert
+// ---------------------------------------------
+func (f *DataField) getTimeFormat() string {
+	if f.Format != "" {
+		return f.Format
+	}
+	return DEFAULT_TIME_FORMAT
+}
+
+// getBoolFormat returns the strings for true and false. e.g. Format = "Y/N"
+func (f *DataField) getBoolFormat() (string, string) {
+	ss := strings.Split(f.Format, "/")
+	if len(ss) == 2 {
+		return ss[0], ss[1]
+	}
+	return "true", "false"
+}
+
+func (f *DataField) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(f.getTimeFormat())
+}
+
+func (f *DataField) parseTime(s string) (time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(f.getTimeFormat(), strings.TrimSpace(s), time.Local)
+	if err != nil {
+		return t, fmt.Errorf("taps: %s: cannot convert %q to time: %w", getBaseName(f.Name), s, err)
+	}
+	return t, nil
+}
+
+func (f *DataField) formatBool(b bool) string {
+	t, fs := f.getBoolFormat()
+	if b {
+		return t
+	}
+	return fs
+}
+
+func (f *DataField) parseBool(s string) (bool, error) {
+	t, fs := f.getBoolFormat()
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.EqualFold(s, t):
+		return true, nil
+	case strings.EqualFold(s, fs), s == "":
+		return false, nil
+	}
+	switch strings.ToLower(s) {
+	case "true", "t", "yes", "y", "on", "1":
+		return true, nil
+	case "false", "f", "no", "n", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("taps: %s: cannot convert %q to bool", getBaseName(f.Name), s)
+}
+
+func (f *DataField) formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return f.formatTime(v.Interface().(time.Time)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return f.formatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.numberPicture(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f.numberPicture(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return f.formatFloat(v.Float(), v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("taps: %s: unsupported type %s", getBaseName(f.Name), v.Type())
+}
+
+func (f *DataField) parseValue(s string, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if strings.TrimSpace(s) == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t, err := f.parseTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	name := getBaseName(f.Name)
+	n := strings.TrimSpace(s)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := f.parseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n == "" {
+			v.SetInt(0)
+			return nil
+		}
+		x, err := strconv.ParseInt(n, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("taps: %s: cannot convert %q to %s: %w", name, s, v.Type(), err)
+		}
+		v.SetInt(x)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n == "" {
+			v.SetUint(0)
+			return nil
+		}
+		x, err := strconv.ParseUint(n, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("taps: %s: cannot convert %q to %s: %w", name, s, v.Type(), err)
+		}
+		v.SetUint(x)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if n == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		x, err := strconv.ParseFloat(n, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("taps: %s: cannot convert %q to %s: %w", name, s, v.Type(), err)
+		}
+		v.SetFloat(x)
+		return nil
+	}
+	return fmt.Errorf("taps: %s: unsupported type %s", name, v.Type())
+}
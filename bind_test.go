@@ -0,0 +1,66 @@
+package taps
+
+import (
+	"testing"
+)
+
+const bindGridDoc = `
+[[Field]]
+Name = "G"
+X = 1
+Y = 1
+Cols = 2
+Rows = 2
+FieldLen = 12
+  [[Field.GridFields]]
+  Name = "A"
+  X = 1
+  Y = 1
+  FieldLen = 5
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+  [[Field.GridFields]]
+  Name = "B"
+  X = 7
+  Y = 1
+  FieldLen = 5
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+  Attr = "N"
+`
+
+type bindCell struct {
+	A string `taps:"A"`
+	B int    `taps:"B"`
+}
+
+func TestBindGrid(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(bindGridDoc, testStyleMatrix, "")
+	in := struct {
+		G [][]bindCell `taps:"G"`
+	}{G: [][]bindCell{{{"a", 1}, {"b", 2}}, {{"c", 3}, {"d", 4}}}}
+	if err := p.Load(&in); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Get(p.GetGridFieldName("A", 1, 0)); got != "b" {
+		t.Errorf("A(1, 0) = %q, want b", got)
+	}
+
+	var out struct {
+		G [][]bindCell `taps:"G"`
+	}
+	if err := p.Save(&out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.G) != 2 || len(out.G[1]) != 2 || out.G[1][0] != (bindCell{"c", 3}) {
+		t.Errorf("Save = %v", out.G)
+	}
+
+	var bad struct {
+		G [][]string `taps:"G"`
+	}
+	if err := p.Save(&bad); err == nil {
+		t.Error("Save of [][]string to a grid with two GridFields succeeded")
+	}
+}
@@ -0,0 +1,173 @@
+package taps
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ---------------------------------------------
+// Export CSV
+// ---------------------------------------------
+// ExportCSV writes the list or grid field n as CSV with a header line.
+// A grid is written one record per (col, row), in order of row and col,
+// with the names of GridFields as the header.
+func (p *Panel) ExportCSV(n string, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if p.isGridName(n) {
+		names := p.getGridColumns(n)
+		if len(names) == 0 {
+			return fmt.Errorf("taps: %s: no field in the grid", n)
+		}
+		cols, rows := p.getGridSize(names[0])
+		cw.Write(names)
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				var record []string
+				for _, name := range names {
+					record = append(record, p.getCellValue(p.GetGridFieldName(name, col, row)))
+				}
+				cw.Write(record)
+			}
+		}
+	} else {
+		if p.getFirstList(n) == nil {
+			return fmt.Errorf("taps: %s: not a list or grid field", n)
+		}
+		cw.Write([]string{n})
+		for _, s := range p.GetList(n) {
+			cw.Write([]string{s})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// getGridColumns returns the names of all fields in GridFields, in order
+// of the definition. They are the columns of CSV and database/sql.
+func (p *Panel) getGridColumns(n string) []string {
+	var names []string
+	for _, f := range p.Field {
+		if f.gridName == n && len(f.GridFields) > 0 {
+			for _, g := range f.GridFields {
+				names = append(names, g.Name)
+			}
+			break
+		}
+	}
+	return names
+}
+
+// getCellValue returns the data of the grid cell. The rows of a list
+// cell are joined with "\n".
+func (p *Panel) getCellValue(n string) string {
+	if p.getFirstList(n) != nil {
+		return strings.Join(p.GetList(n), "\n")
+	}
+	return p.Get(n)
+}
+
+// ---------------------------------------------
+// Import CSV
+// ---------------------------------------------
+// ImportCSV reads CSV with a header line into the list or grid field n.
+// For a grid, the columns are mapped to GridFields by the header, and the
+// other columns are ignored. A list grows beyond the visible rows, while
+// a grid returns an error if there are more records than cells.
+func (p *Panel) ImportCSV(n string, r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return fmt.Errorf("taps: %s: %w", n, err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("taps: %s: no header", n)
+	}
+	header, records := records[0], records[1:]
+
+	if p.isGridName(n) {
+		return p.importGridCSV(n, header, records)
+	}
+
+	f := p.getFirstList(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: not a list or grid field", n)
+	}
+	k := 0
+	for j, x := range header {
+		if strings.TrimSpace(x) == n {
+			k = j
+		}
+	}
+	listData := []string{}
+	for _, record := range records {
+		s := ""
+		if k < len(record) {
+			s = record[k]
+		}
+		if err := f.checkValue(s); err != nil {
+			return err
+		}
+		listData = append(listData, s)
+	}
+	p.StoreList(listData, n)
+	return nil
+}
+
+func (p *Panel) importGridCSV(n string, header []string, records [][]string) error {
+	names := p.getGridColumns(n)
+	if len(names) == 0 {
+		return fmt.Errorf("taps: %s: no field in the grid", n)
+	}
+	columns := make(map[string]int)
+	for k, x := range header {
+		for _, name := range names {
+			if strings.TrimSpace(x) == name {
+				columns[name] = k
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("taps: %s: no column matches the grid", n)
+	}
+
+	cols, rows := p.getGridSize(names[0])
+	if len(records) > cols*rows {
+		return fmt.Errorf("taps: %s: %d records are more than %d cells", n, len(records), cols*rows)
+	}
+
+	for k := 0; k < cols*rows; k++ {
+		col, row := k%cols, k/cols
+		for _, name := range names {
+			j, ok := columns[name]
+			if !ok {
+				continue
+			}
+			s := ""
+			if k < len(records) && j < len(records[k]) {
+				s = records[k][j]
+			}
+			if err := p.storeCellValue(s, p.GetGridFieldName(name, col, row)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Panel) storeCellValue(s string, n string) error {
+	f := p.getFirstList(n)
+	if f == nil {
+		return p.storeValue(s, n)
+	}
+	listData := strings.Split(s, "\n")
+	for _, x := range listData {
+		if err := f.checkValue(x); err != nil {
+			return err
+		}
+	}
+	p.StoreList(listData, n)
+	return nil
+}
@@ -0,0 +1,248 @@
+package taps
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	FORMAT_JSON = "json"
+	FORMAT_TOML = "toml"
+)
+
+// gridRecord is the cells of a grid at (col, row).
+type gridRecord struct {
+	col, row int
+	data     map[string]any
+}
+
+// ---------------------------------------------
+// Export Data
+// ---------------------------------------------
+// ExportData returns the data of the edit fields as JSON or TOML.
+// List fields are arrays, and grid fields are arrays of records keyed
+// by the names of GridFields.
+func (p *Panel) ExportData(format string) ([]byte, error) {
+	data := p.exportData()
+	switch strings.ToLower(format) {
+	case FORMAT_JSON:
+		return json.MarshalIndent(data, "", "  ")
+	case FORMAT_TOML:
+		return toml.Marshal(data)
+	}
+	return nil, fmt.Errorf("taps: ExportData: unknown format %q", format)
+}
+
+func (p *Panel) exportData() map[string]any {
+	data := make(map[string]any)
+	grids := make(map[string][]*gridRecord)
+
+	for _, f := range p.Field {
+		if f.Name == "" || !isEdit(f) {
+			continue
+		}
+		if isListMode(f) && !strings.HasSuffix(f.Name, LIST_SEP+"000") {
+			continue
+		}
+		name := strings.Split(f.Name, LIST_SEP)[0]
+		var v any = p.Get(name)
+		if isListMode(f) {
+			v = p.getListValue(name)
+		}
+
+		if f.gridName == "" {
+			data[name] = v
+			continue
+		}
+
+		col, row := getGridPos(name)
+		var rec *gridRecord
+		for _, r := range grids[f.gridName] {
+			if r.col == col && r.row == row {
+				rec = r
+			}
+		}
+		if rec == nil {
+			rec = &gridRecord{col: col, row: row, data: make(map[string]any)}
+			grids[f.gridName] = append(grids[f.gridName], rec)
+		}
+		rec.data[getBaseName(name)] = v
+	}
+
+	for gridName, recs := range grids {
+		sort.SliceStable(recs, func(a, b int) bool {
+			if recs[a].row != recs[b].row {
+				return recs[a].row < recs[b].row
+			}
+			return recs[a].col < recs[b].col
+		})
+		var rows []map[string]any
+		for _, r := range recs {
+			rows = append(rows, r.data)
+		}
+		data[gridName] = rows
+	}
+	return data
+}
+
+func (p *Panel) getListValue(n string) []string {
+	listData := p.GetList(n)
+	if listData == nil {
+		return []string{}
+	}
+	return listData
+}
+
+// getGridPos returns col and row of the grid cell name.
+func getGridPos(name string) (int, int) {
+	ss := strings.Split(name, GRID_SEP)
+	if len(ss) != 2 || len(ss[1]) < 7 {
+		return 0, 0
+	}
+	col, _ := strconv.Atoi(ss[1][0:3])
+	row, _ := strconv.Atoi(ss[1][4:7])
+	return col, row
+}
+
+// ---------------------------------------------
+// Import Data
+// ---------------------------------------------
+// ImportData stores the data written by ExportData.
+func (p *Panel) ImportData(format string, r io.Reader) error {
+	var data map[string]any
+	var err error
+	switch strings.ToLower(format) {
+	case FORMAT_JSON:
+		err = json.NewDecoder(r).Decode(&data)
+	case FORMAT_TOML:
+		err = toml.NewDecoder(r).Decode(&data)
+	default:
+		return fmt.Errorf("taps: ImportData: unknown format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("taps: ImportData: %w", err)
+	}
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := p.importValue(name, data[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Panel) importValue(name string, v any) error {
+	if p.isGridName(name) {
+		rows, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("taps: %s: grid data must be an array", name)
+		}
+		return p.importGrid(name, rows)
+	}
+
+	if ss, ok := v.([]any); ok {
+		f := p.getFirstList(name)
+		if f == nil {
+			return fmt.Errorf("taps: %s: not a list field", name)
+		}
+		listData, err := toStrings(name, ss)
+		if err != nil {
+			return err
+		}
+		for _, s := range listData {
+			if err := f.checkValue(s); err != nil {
+				return err
+			}
+		}
+		p.StoreList(listData, name)
+		return nil
+	}
+
+	s, err := toString(name, v)
+	if err != nil {
+		return err
+	}
+	return p.storeValue(s, name)
+}
+
+func (p *Panel) isGridName(name string) bool {
+	for _, f := range p.Field {
+		if f.gridName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// importGrid stores the records to the grid in order of row and col.
+func (p *Panel) importGrid(name string, rows []any) error {
+	var cols int
+	for _, f := range p.Field {
+		if f.gridName == name {
+			cols, _ = p.getGridSize(getBaseName(f.Name))
+			break
+		}
+	}
+
+	for k, x := range rows {
+		rec, ok := x.(map[string]any)
+		if !ok {
+			return fmt.Errorf("taps: %s: grid record must be a table", name)
+		}
+		col, row := k%cols, k/cols
+		for n, v := range rec {
+			cell := p.GetGridFieldName(n, col, row)
+			f := p.GetDataField(cell)
+			if f == nil {
+				f = p.getFirstList(cell)
+			}
+			if f == nil || f.gridName != name {
+				return fmt.Errorf("taps: %s: no grid cell %s (%d, %d)", name, n, col, row)
+			}
+			if err := p.importValue(cell, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func toString(name string, v any) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case []any, map[string]any:
+		return "", fmt.Errorf("taps: %s: unsupported value %v", name, v)
+	}
+	return fmt.Sprint(v), nil
+}
+
+func toStrings(name string, vs []any) ([]string, error) {
+	var ss []string
+	for _, v := range vs {
+		s, err := toString(name, v)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+	return ss, nil
+}
@@ -0,0 +1,65 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Event is the result of ReadEvent.
+//
+// Name is the name returned by Read, "" for the ExitKey of the panel.
+// Field is the name of the focused field when Read returned. For a list
+// field, Index is the row of the list data, including listStart. For a
+// grid field, Grid is the name of the grid and Col, Row is the cell.
+// Index, Col and Row are -1 if the field is not a list or a grid.
+type Event struct {
+	Key       tcell.Key
+	Rune      rune
+	Modifiers tcell.ModMask
+	Name      string
+	Field     string
+	Mouse     bool
+	Buttons   tcell.ButtonMask
+	X, Y      int
+	Index     int
+	Grid      string
+	Col, Row  int
+}
+
+// ---------------------------------------------
+// Read Event
+// ---------------------------------------------
+// ReadEvent is Read which returns the key with the rune and the modifiers,
+// the mouse position, and the row of the list or the cell of the grid.
+func (p *Panel) ReadEvent() Event {
+	cKey, n := p.read2(p.SelectFocus)
+	e := Event{Key: cKey, Name: n, Index: -1, Col: -1, Row: -1}
+
+	if p.lastKey != nil {
+		e.Rune = p.lastKey.Rune()
+		e.Modifiers = p.lastKey.Modifiers()
+	}
+	if p.lastMouse != nil {
+		e.Mouse = true
+		e.Buttons = p.lastMouse.Buttons()
+		e.Modifiers = p.lastMouse.Modifiers()
+		e.X, e.Y = p.lastMouse.Position()
+	}
+
+	if p.SelectFocus < 0 || p.SelectFocus >= len(p.Field) {
+		return e
+	}
+	f := p.Field[p.SelectFocus]
+	e.Field = f.Name
+	if isListMode(f) {
+		if s := p.getFirstList(f.Name); s != nil {
+			// The lines of a wrapped row are not counted.
+			_, cnt := p.getListCountUntil(f.Name)
+			e.Index = s.listStart + cnt
+		}
+	}
+	if f.gridName != "" {
+		e.Grid = f.gridName
+		e.Col, e.Row = getGridPos(f.Name)
+	}
+	return e
+}
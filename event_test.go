@@ -0,0 +1,40 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+const eventDoc = `
+ExitKey = ["F3"]
+
+[[Field]]
+Name = "L"
+X = 1
+Y = 1
+Rows = 4
+FieldLen = 5
+Style = "edit, edit_focus"
+FieldType = "edit"
+`
+
+func TestReadEventWrappedList(t *testing.T) {
+	s := newTestScreen(t)
+	p := NewPanel(eventDoc, testStyleMatrix, "")
+	p.StoreList([]string{"abcdefgh", "x", "y"}, "L")
+	p.Say()
+
+	// "abcdefgh" takes L_$$000 and L_$$001, so "x" is on L_$$002.
+	p.SelectFocus = p.GetFieldNumber(p.GetListFieldName("L", 2))
+	s.InjectKey(tcell.KeyF3, 0, tcell.ModNone)
+	e := p.ReadEvent()
+	if e.Field != p.GetListFieldName("L", 2) {
+		t.Fatalf("Field = %q", e.Field)
+	}
+	if e.Index != 1 {
+		t.Errorf("Index = %d, want 1", e.Index)
+	}
+	if got := p.GetList("L")[e.Index]; got != "x" {
+		t.Errorf("row = %q, want x", got)
+	}
+}
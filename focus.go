@@ -0,0 +1,186 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"sort"
+)
+
+// ---------------------------------------------
+// Tab order
+// ---------------------------------------------
+func (p *Panel) hasTabIndex() bool {
+	for _, f := range p.Field {
+		if f.TabIndex != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tabOrder returns the numbers of the fields in the order of TabIndex.
+// Fields with the same TabIndex keep the order of the definition, except
+// that the cells of a grid go by row, column and field in GridFields.
+// Fields without TabIndex follow the others.
+func (p *Panel) tabOrder() []int {
+	order := make([]int, len(p.Field))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		f, g := p.Field[order[a]], p.Field[order[b]]
+		x, y := f.TabIndex, g.TabIndex
+		if x == y {
+			return gridCellLess(f, g)
+		}
+		if x == 0 || y == 0 {
+			return x != 0 && y == 0
+		}
+		return x < y
+	})
+	return order
+}
+
+// gridCellLess reports whether the cell f comes before the cell g of the
+// same grid in the order of row, column and field in GridFields.
+func gridCellLess(f, g *DataField) bool {
+	if f.gridName == "" || f.gridName != g.gridName {
+		return false
+	}
+	fc, fr := getGridPos(f.Name)
+	gc, gr := getGridPos(g.Name)
+	if fr != gr {
+		return fr < gr
+	}
+	if fc != gc {
+		return fc < gc
+	}
+	return f.gridIndex < g.gridIndex
+}
+
+func isFocusable(f *DataField) bool {
+	return !isDisabled(f) && !isLabel(f) && (isEdit(f) || len(f.RData) > 0)
+}
+
+// tabSelect moves the focus by Tab, Backtab and Enter in the order of
+// TabIndex. Enter on an edit field goes to the next edit field.
+func (p *Panel) tabSelect(i int, cKey tcell.Key) int {
+	dir := 1
+	if cKey == tcell.KeyBacktab {
+		dir = -1
+	}
+	order := p.tabOrder()
+	k := 0
+	for n, x := range order {
+		if x == i {
+			k = n
+		}
+	}
+
+	j := INVALID_KEY
+	save := INVALID_KEY
+	for k += dir; k >= 0 && k < len(order); k += dir {
+		f := p.Field[order[k]]
+		if !isFocusable(f) {
+			continue
+		}
+		if cKey == tcell.KeyEnter && isEdit(p.Field[i]) && !isEdit(f) {
+			if save == INVALID_KEY {
+				save = order[k]
+			}
+			continue
+		}
+		j = order[k]
+		break
+	}
+	if j == INVALID_KEY {
+		j = save
+	}
+	SetNormalStyle(p.Field[i])
+	p.Field[i].Say()
+	if j == INVALID_KEY {
+		return i
+	}
+	return j
+}
+
+// ---------------------------------------------
+// Arrow
+// ---------------------------------------------
+// arrowSelect moves the focus to the nearest field in the direction of
+// the arrow key on the screen. The distance is the distance along the
+// direction plus twice the distance across it.
+func (p *Panel) arrowSelect(i int, cKey tcell.Key) int {
+	cur := p.Field[i]
+	cx, cy := GetFieldX(cur.X), GetFieldY(cur.Y)
+	cw := getFieldWidth(cur)
+
+	j := INVALID_KEY
+	best := 0
+	for k, f := range p.Field {
+		if k == i || !isFocusable(f) {
+			continue
+		}
+		fx, fy := GetFieldX(f.X), GetFieldY(f.Y)
+		dx := abs(fx - cx)
+		dy := abs(fy - cy)
+		var score int
+		switch cKey {
+		case tcell.KeyUp, tcell.KeyDown:
+			if (cKey == tcell.KeyUp && fy >= cy) || (cKey == tcell.KeyDown && fy <= cy) {
+				continue
+			}
+			// Fields overlapping in columns are just above or below.
+			dx = 0
+			if fx >= cx+cw {
+				dx = fx - (cx + cw) + 1
+			} else if fx+getFieldWidth(f) <= cx {
+				dx = cx - (fx + getFieldWidth(f)) + 1
+			}
+			score = dy + 2*dx
+		case tcell.KeyLeft, tcell.KeyRight:
+			if (cKey == tcell.KeyLeft && fx >= cx) || (cKey == tcell.KeyRight && fx <= cx) {
+				continue
+			}
+			score = dx + 2*dy
+		default:
+			return i
+		}
+		if j == INVALID_KEY || score < best {
+			j, best = k, score
+		}
+	}
+
+	if j == INVALID_KEY {
+		return i
+	}
+	SetNormalStyle(cur)
+	cur.Say()
+	return j
+}
+
+// isGridEdge reports whether Left or Right goes out of the edit field of
+// the grid, to the next cell.
+func (p *Panel) isGridEdge(i int, cKey tcell.Key) bool {
+	f := p.Field[i]
+	if f.gridName == "" || isListMode(f) || !isEdit(f) || isDisabled(f) {
+		return false
+	}
+	return (cKey == tcell.KeyLeft && f.hDataPos == 0) || (cKey == tcell.KeyRight && f.hDataPos >= len(f.RData))
+}
+
+func getFieldWidth(f *DataField) int {
+	if f.FieldLen > 0 {
+		return GetFieldX(f.FieldLen)
+	}
+	if w := len(f.RData); w > 0 {
+		return w
+	}
+	return 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,69 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+const focusGridDoc = `
+[[Field]]
+Name = "Last"
+X = 1
+Y = 5
+FieldLen = 5
+Style = "edit, edit_focus"
+FieldType = "edit"
+TabIndex = 3
+
+[[Field]]
+Name = "G"
+X = 1
+Y = 1
+Cols = 2
+Rows = 2
+FieldLen = 12
+TabIndex = 2
+  [[Field.GridFields]]
+  Name = "A"
+  X = 1
+  Y = 1
+  FieldLen = 5
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+  [[Field.GridFields]]
+  Name = "B"
+  X = 7
+  Y = 1
+  FieldLen = 5
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+
+[[Field]]
+Name = "First"
+X = 1
+Y = 6
+FieldLen = 5
+Style = "edit, edit_focus"
+FieldType = "edit"
+TabIndex = 1
+`
+
+func TestTabOrderGrid(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(focusGridDoc, testStyleMatrix, "")
+	want := []string{"First"}
+	for row := 0; row < 2; row++ {
+		for col := 0; col < 2; col++ {
+			want = append(want, p.GetGridFieldName("A", col, row), p.GetGridFieldName("B", col, row))
+		}
+	}
+	want = append(want, "Last")
+
+	i := p.GetFieldNumber("First")
+	for n, name := range want {
+		if got := p.Field[i].Name; got != name {
+			t.Fatalf("Tab %d: focus on %q, want %q", n, got, name)
+		}
+		i = p.tabSelect(i, tcell.KeyTab)
+	}
+}
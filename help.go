@@ -0,0 +1,189 @@
+package taps
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"strings"
+)
+
+// ---------------------------------------------
+// Help
+// ---------------------------------------------
+// GetFieldHelp returns Help of the field n, or the help of the panel
+// if the field has no help.
+func (p *Panel) GetFieldHelp(n string) string {
+	if f := p.GetDataField(n); f != nil && f.Help != "" {
+		return f.Help
+	}
+	return p.help
+}
+
+func (p *Panel) isHelpKey(ev *tcell.EventKey) bool {
+	if len(p.HelpKey) == 0 {
+		return ev.Key() == tcell.KeyF1
+	}
+	return isExitKey(p.HelpKey, ev)
+}
+
+// showHelp shows the help of field i in HelpField, or in the overlay if
+// the panel has no HelpField.
+func (p *Panel) showHelp(i int) {
+	help := p.GetFieldHelp(p.Field[i].Name)
+	if help == "" {
+		return
+	}
+	if f := p.GetDataField(p.HelpField); f != nil {
+		p.sayMessage(f, strings.ReplaceAll(help, "\n", " "))
+		return
+	}
+	p.helpOverlay(help)
+	p.Field[i].Say()
+}
+
+// ---------------------------------------------
+// Help overlay
+// ---------------------------------------------
+// wrapHelp splits the help into the lines of width w.
+func wrapHelp(help string, w int) []string {
+	var lines []string
+	for _, l := range strings.Split(help, "\n") {
+		line := []rune{}
+		x := 0
+		for _, r := range l {
+			if x+runewidth.RuneWidth(r) > w {
+				lines = append(lines, string(line))
+				line = []rune{}
+				x = 0
+			}
+			line = append(line, r)
+			x += runewidth.RuneWidth(r)
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// helpOverlay shows the help in the box in the middle of the screen until
+// Esc, Enter or the help key is pressed. The box is written to the screen
+// only, and the cells under it are restored from the shadow buffer.
+func (p *Panel) helpOverlay(help string) {
+	style := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+	if p.HelpStyle != "" {
+		style, _ = getStyle(p.HelpStyle, p.styleMatrix)
+	}
+
+	mx, my := GetWindowSize()
+	w := 0
+	for _, l := range strings.Split(help, "\n") {
+		if w < runewidth.StringWidth(l) {
+			w = runewidth.StringWidth(l)
+		}
+	}
+	if w > mx-5 {
+		w = mx - 5
+	}
+	if w < 10 {
+		w = 10
+	}
+	lines := wrapHelp(help, w)
+	h := len(lines)
+	if h > my-3 {
+		h = my - 3
+	}
+	sx := (mx - w - 3) / 2
+	sy := (my - h - 1) / 2
+	ex := sx + w + 3
+	ey := sy + h + 1
+
+	var pending []tcell.Event
+	top := 0
+	EraseCursor()
+	for {
+		drawHelpBox(lines, top, sx, sy, ex, ey, style)
+		ev := taps.screen.PollEvent()
+		switch ev := ev.(type) {
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyEnter || p.isHelpKey(ev):
+				top = -1
+			case ev.Key() == tcell.KeyUp:
+				top--
+			case ev.Key() == tcell.KeyDown:
+				top++
+			case ev.Key() == tcell.KeyPgUp:
+				top -= h
+			case ev.Key() == tcell.KeyPgDn:
+				top += h
+			case ev.Key() == tcell.KeyHome:
+				top = 0
+			case ev.Key() == tcell.KeyEnd:
+				top = len(lines) - h
+			}
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.WheelUp != 0 {
+				top--
+			}
+			if ev.Buttons()&tcell.WheelDown != 0 {
+				top++
+			}
+		case *tcell.EventInterrupt, *tcell.EventClipboard:
+			pending = append(pending, ev)
+		}
+		if top < 0 {
+			break
+		}
+		if top > len(lines)-h {
+			top = len(lines) - h
+		}
+	}
+
+	for y := sy; y <= ey; y++ {
+		for x := sx; x <= ex; x++ {
+			restoreCell(x, y)
+		}
+	}
+	drawToasts()
+	for _, ev := range pending {
+		taps.screen.PostEvent(ev)
+	}
+}
+
+func drawHelpBox(lines []string, top, sx, sy, ex, ey int, style tcell.Style) {
+	for y := sy; y <= ey; y++ {
+		for x := sx; x <= ex; x++ {
+			r := ' '
+			switch {
+			case y == sy && x == sx:
+				r = '┌'
+			case y == sy && x == ex:
+				r = '┐'
+			case y == ey && x == sx:
+				r = '└'
+			case y == ey && x == ex:
+				r = '┘'
+			case y == sy || y == ey:
+				r = '─'
+			case x == sx || x == ex:
+				r = '│'
+			}
+			taps.screen.SetContent(x, y, r, nil, style)
+		}
+	}
+
+	drawHelpText(" Help ", sx+2, sy, style)
+	if len(lines) > ey-sy-1 {
+		drawHelpText(fmt.Sprintf(" %d/%d ", top+1, len(lines)), sx+2, ey, style)
+	}
+	for k := 0; k < ey-sy-1 && top+k < len(lines); k++ {
+		drawHelpText(lines[top+k], sx+2, sy+1+k, style)
+	}
+	taps.screen.Show()
+}
+
+func drawHelpText(s string, x, y int, style tcell.Style) {
+	for _, r := range s {
+		taps.screen.SetContent(x, y, r, nil, style)
+		x += runewidth.RuneWidth(r)
+	}
+}
@@ -0,0 +1,120 @@
+package taps
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// keySpec is a key of ExitKey or KeyMap. A name of tcell.KeyNames
+// without modifiers matches the key with any modifiers.
+type keySpec struct {
+	key    tcell.Key
+	r      rune
+	mod    tcell.ModMask
+	anyMod bool
+}
+
+var keyModifiers = []struct {
+	name string
+	mod  tcell.ModMask
+}{
+	{"Shift+", tcell.ModShift},
+	{"Ctrl+", tcell.ModCtrl},
+	{"Alt+", tcell.ModAlt},
+	{"Meta+", tcell.ModMeta},
+}
+
+// ---------------------------------------------
+// Parse key
+// ---------------------------------------------
+// parseKeySpec parses the key specification.
+//
+//	"F3", "Ctrl-A", "Left"     a name of tcell.KeyNames, or Ctrl-H, Ctrl-I,
+//	                           Ctrl-M, Ctrl-[, with any modifiers
+//	"Alt+S", "Shift+F5"        modifiers and a key name or a character
+//	"Ctrl+Right", "Ctrl+A"
+//	"?", "Rune:?"              a character without modifiers
+func parseKeySpec(s string) (keySpec, error) {
+	if k, ok := getKeyByName(s); ok {
+		return keySpec{key: k, anyMod: true}, nil
+	}
+
+	mod := tcell.ModNone
+	x := s
+	for found := true; found; {
+		found = false
+		for _, m := range keyModifiers {
+			if strings.HasPrefix(x, m.name) && len(x) > len(m.name) {
+				mod |= m.mod
+				x = x[len(m.name):]
+				found = true
+			}
+		}
+	}
+
+	if k, ok := getKeyByName(x); ok {
+		return keySpec{key: k, mod: mod}, nil
+	}
+
+	x = strings.TrimPrefix(x, "Rune:")
+	if strings.HasPrefix(x, "Rune[") && strings.HasSuffix(x, "]") {
+		x = x[len("Rune[") : len(x)-1]
+	}
+	if utf8.RuneCountInString(x) != 1 {
+		return keySpec{}, fmt.Errorf("taps: unknown key %q", s)
+	}
+	r, _ := utf8.DecodeRuneInString(x)
+	if mod&tcell.ModCtrl != 0 && unicode.IsLetter(r) && r < unicode.MaxASCII {
+		return keySpec{key: tcell.KeyCtrlA + tcell.Key(unicode.ToUpper(r)-'A'), mod: mod}, nil
+	}
+	return keySpec{key: tcell.KeyRune, r: r, mod: mod &^ tcell.ModShift}, nil
+}
+
+// ctrlKeyNames are the control keys which tcell.KeyNames names after the
+// keys sending them, e.g. Ctrl-H is "Backspace".
+var ctrlKeyNames = map[string]tcell.Key{
+	"Ctrl-H": tcell.KeyCtrlH,
+	"Ctrl-I": tcell.KeyCtrlI,
+	"Ctrl-M": tcell.KeyCtrlM,
+	"Ctrl-[": tcell.KeyCtrlLeftSq,
+}
+
+func getKeyByName(s string) (tcell.Key, bool) {
+	for k, v := range tcell.KeyNames {
+		if v == s {
+			return k, true
+		}
+	}
+	if k, ok := ctrlKeyNames[s]; ok {
+		return k, true
+	}
+	return tcell.KeyNUL, false
+}
+
+// match reports whether ev is the key. A character with modifiers
+// matches both cases, e.g. "Alt+S" matches Alt-s and Alt-S.
+func (k keySpec) match(ev *tcell.EventKey) bool {
+	if ev.Key() != k.key {
+		return false
+	}
+	if k.anyMod {
+		return true
+	}
+	if k.key != tcell.KeyRune {
+		return ev.Modifiers() == k.mod
+	}
+	mod := ev.Modifiers() &^ tcell.ModShift
+	if k.mod == tcell.ModNone {
+		return mod == tcell.ModNone && ev.Rune() == k.r
+	}
+	return mod == k.mod && unicode.ToLower(ev.Rune()) == unicode.ToLower(k.r)
+}
+
+// LastKey returns the key event which made Read return, or nil if Read
+// returned by the mouse.
+func (p *Panel) LastKey() *tcell.EventKey {
+	return p.lastKey
+}
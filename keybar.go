@@ -0,0 +1,66 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// keyBarItem is the position of a caption in the function-key bar.
+type keyBarItem struct {
+	x0, x1, y int
+	key       string
+}
+
+// ---------------------------------------------
+// Function-key bar
+// ---------------------------------------------
+// sayKeyBar writes the exit keys with ExitKeyLabel at KeyBarX, KeyBarY of
+// the panel, in order of ExitKey. The bar is wrapped at the right end of
+// the panel.
+func (p *Panel) sayKeyBar() {
+	p.keyBar = nil
+	if p.KeyBarY == nil {
+		return
+	}
+	style, _ := getStyle(p.KeyBarStyle, p.styleMatrix)
+	mx, _ := GetWindowSize()
+	if p.EndX > 0 && p.EndX != 9999 && GetFieldX(p.EndX) < mx {
+		mx = GetFieldX(p.EndX)
+	}
+	sx := GetFieldX(p.StartX) + GetFieldX(p.KeyBarX)
+	x := sx
+	y := GetFieldY(p.StartY) + GetFieldY(*p.KeyBarY)
+
+	for _, k := range p.ExitKey {
+		label := p.ExitKeyLabel[k]
+		if label == "" {
+			continue
+		}
+		s := k + " " + label
+		w := runewidth.StringWidth(s)
+		if x > sx && x+w > mx {
+			x = sx
+			y++
+		}
+		ConsoleOut(s, x, y, style)
+		p.keyBar = append(p.keyBar, keyBarItem{x0: x, x1: x + w, y: y, key: k})
+		x += w + 2
+	}
+	Show()
+}
+
+// getClickedKey returns the key event of the caption clicked.
+func (p *Panel) getClickedKey(e *tcell.EventMouse) *tcell.EventKey {
+	x, y := e.Position()
+	for _, item := range p.keyBar {
+		if y != item.y || x < item.x0 || x >= item.x1 {
+			continue
+		}
+		k, err := parseKeySpec(item.key)
+		if err != nil {
+			return nil
+		}
+		return tcell.NewEventKey(k.key, k.r, k.mod)
+	}
+	return nil
+}
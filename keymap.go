@@ -0,0 +1,307 @@
+package taps
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	ACTION_NONE     = "none"
+	ACTION_UNDO     = "undo"
+	ACTION_REDO     = "redo"
+	ACTION_YANK     = "yank"
+	ACTION_YANK_POP = "yank-pop"
+)
+
+// KeyMap maps keys to the names of the actions. Normal is the keys of the
+// normal (command) mode of a modal key map like vi; Keys is then the keys
+// of the insert mode. Keys which are not in the map work as usual.
+//
+//	Name = "my keys"
+//	[Keys]
+//	"Home" = "line-start"
+//	"Ctrl-K" = "none"
+//
+// The keys are written as ExitKey, e.g. "Ctrl-A", "Alt+y", "Ctrl+Right".
+type KeyMap struct {
+	Name   string
+	Keys   map[string]string
+	Normal map[string]string
+	keys   []keyBinding
+	normal []keyBinding
+}
+
+type keyBinding struct {
+	keySpec
+	action string
+}
+
+// keyAction is the key which does the action, and the mode after it.
+// An empty mode keeps the current mode.
+type keyAction struct {
+	keySpec
+	mode string
+}
+
+const (
+	KEY_MODE_INSERT = "insert"
+	KEY_MODE_NORMAL = "normal"
+)
+
+var keyActions = map[string]keyAction{
+	"cursor-left":          {keySpec: keySpec{key: tcell.KeyLeft}},
+	"cursor-right":         {keySpec: keySpec{key: tcell.KeyRight}},
+	"cursor-up":            {keySpec: keySpec{key: tcell.KeyUp}},
+	"cursor-down":          {keySpec: keySpec{key: tcell.KeyDown}},
+	"word-left":            {keySpec: keySpec{key: tcell.KeyLeft, mod: tcell.ModCtrl}},
+	"word-right":           {keySpec: keySpec{key: tcell.KeyRight, mod: tcell.ModCtrl}},
+	"line-start":           {keySpec: keySpec{key: tcell.KeyCtrlA}},
+	"line-end":             {keySpec: keySpec{key: tcell.KeyCtrlE}},
+	"delete-char":          {keySpec: keySpec{key: tcell.KeyDelete}},
+	"delete-backward-char": {keySpec: keySpec{key: tcell.KeyBackspace2}},
+	"kill-line":            {keySpec: keySpec{key: tcell.KeyCtrlK}},
+	"kill-word":            {keySpec: keySpec{key: tcell.KeyRune, r: 'd', mod: tcell.ModAlt}},
+	"backward-kill-word":   {keySpec: keySpec{key: tcell.KeyCtrlW}},
+	"kill-line-start":      {keySpec: keySpec{key: tcell.KeyCtrlU}},
+	"insert-line":          {keySpec: keySpec{key: tcell.KeyCtrlO}},
+	"toggle-overwrite":     {keySpec: keySpec{key: tcell.KeyInsert}},
+	"newline":              {keySpec: keySpec{key: tcell.KeyEnter}},
+	"page-up":              {keySpec: keySpec{key: tcell.KeyPgUp}},
+	"page-down":            {keySpec: keySpec{key: tcell.KeyPgDn}},
+	"first-row":            {keySpec: keySpec{key: tcell.KeyHome, mod: tcell.ModCtrl}},
+	"last-row":             {keySpec: keySpec{key: tcell.KeyEnd, mod: tcell.ModCtrl}},
+	"next-field":           {keySpec: keySpec{key: tcell.KeyTab}},
+	"prior-field":          {keySpec: keySpec{key: tcell.KeyBacktab}},
+	"cancel":               {keySpec: keySpec{key: tcell.KeyEscape}},
+	ACTION_YANK:            {keySpec: keySpec{key: tcell.KeyNUL}},
+	ACTION_YANK_POP:        {keySpec: keySpec{key: tcell.KeyNUL}},
+	ACTION_UNDO:            {keySpec: keySpec{key: tcell.KeyNUL}},
+	ACTION_REDO:            {keySpec: keySpec{key: tcell.KeyNUL}},
+	ACTION_NONE:            {keySpec: keySpec{key: tcell.KeyNUL}},
+	// Modal
+	"insert-mode":     {keySpec: keySpec{key: tcell.KeyNUL}, mode: KEY_MODE_INSERT},
+	"normal-mode":     {keySpec: keySpec{key: tcell.KeyNUL}, mode: KEY_MODE_NORMAL},
+	"append":          {keySpec: keySpec{key: tcell.KeyRight}, mode: KEY_MODE_INSERT},
+	"insert-at-start": {keySpec: keySpec{key: tcell.KeyCtrlA}, mode: KEY_MODE_INSERT},
+	"append-at-end":   {keySpec: keySpec{key: tcell.KeyCtrlE}, mode: KEY_MODE_INSERT},
+	"open-line":       {keySpec: keySpec{key: tcell.KeyCtrlO}, mode: KEY_MODE_INSERT},
+}
+
+// ---------------------------------------------
+// Presets
+// ---------------------------------------------
+func NewEmacsKeyMap() *KeyMap {
+	return &KeyMap{
+		Name: "emacs",
+		Keys: map[string]string{
+			"Ctrl-A": "line-start",
+			"Ctrl-E": "line-end",
+			"Ctrl-F": "cursor-right",
+			"Ctrl-B": "cursor-left",
+			"Ctrl-P": "cursor-up",
+			"Ctrl-N": "cursor-down",
+			"Ctrl-D": "delete-char",
+			"Ctrl-H": "delete-backward-char",
+			"Ctrl-K": "kill-line",
+			"Ctrl-U": "kill-line-start",
+			"Ctrl-W": "backward-kill-word",
+			"Alt+f":  "word-right",
+			"Alt+b":  "word-left",
+			"Alt+d":  "kill-word",
+			"Ctrl-O": "insert-line",
+			"Ctrl-Y": ACTION_YANK,
+			"Alt+y":  ACTION_YANK_POP,
+			"Ctrl-Z": ACTION_UNDO,
+			"Ctrl-R": ACTION_REDO,
+		},
+	}
+}
+
+func NewCUAKeyMap() *KeyMap {
+	return &KeyMap{
+		Name: "cua",
+		Keys: map[string]string{
+			"Home":   "line-start",
+			"End":    "line-end",
+			"Ctrl-Z": ACTION_UNDO,
+			"Ctrl-Y": ACTION_REDO,
+			"Ctrl-V": ACTION_YANK,
+			"Ctrl-A": ACTION_NONE,
+			"Ctrl-E": ACTION_NONE,
+			"Ctrl-F": ACTION_NONE,
+			"Ctrl-B": ACTION_NONE,
+			"Ctrl-D": ACTION_NONE,
+			"Ctrl-K": ACTION_NONE,
+			"Ctrl-R": ACTION_NONE,
+			"Alt+y":  ACTION_NONE,
+			"Ctrl-U": ACTION_NONE,
+			"Ctrl-W": ACTION_NONE,
+			"Alt+f":  ACTION_NONE,
+			"Alt+b":  ACTION_NONE,
+			"Alt+d":  ACTION_NONE,
+		},
+	}
+}
+
+// NewViKeyMap returns the modal key map. Fields start in the insert mode,
+// and Esc changes to the normal mode. Esc in the normal mode cancels Read.
+func NewViKeyMap() *KeyMap {
+	return &KeyMap{
+		Name: "vi",
+		Keys: map[string]string{
+			"Esc": "normal-mode",
+		},
+		Normal: map[string]string{
+			"h":      "cursor-left",
+			"l":      "cursor-right",
+			"k":      "cursor-up",
+			"j":      "cursor-down",
+			"0":      "line-start",
+			"$":      "line-end",
+			"x":      "delete-char",
+			"X":      "delete-backward-char",
+			"D":      "kill-line",
+			"e":      "word-right",
+			"b":      "word-left",
+			"p":      ACTION_YANK,
+			"u":      ACTION_UNDO,
+			"Ctrl-R": ACTION_REDO,
+			"i":      "insert-mode",
+			"a":      "append",
+			"I":      "insert-at-start",
+			"A":      "append-at-end",
+			"O":      "open-line",
+			"Left":   "cursor-left",
+			"Right":  "cursor-right",
+		},
+	}
+}
+
+// ---------------------------------------------
+// Load Key Map
+// ---------------------------------------------
+// LoadKeyMap reads the key map from TOML.
+func LoadKeyMap(doc string) (*KeyMap, error) {
+	km := new(KeyMap)
+	if err := toml.Unmarshal([]byte(doc), km); err != nil {
+		return nil, fmt.Errorf("taps: LoadKeyMap: %w", err)
+	}
+	if err := km.compile(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+func (km *KeyMap) compile() error {
+	var err error
+	if km.keys, err = compileKeys(km.Keys); err != nil {
+		return err
+	}
+	km.normal, err = compileKeys(km.Normal)
+	return err
+}
+
+func compileKeys(m map[string]string) ([]keyBinding, error) {
+	var keys []keyBinding
+	for s, action := range m {
+		if _, ok := keyActions[action]; !ok {
+			return nil, fmt.Errorf("taps: %s: unknown action %q", s, action)
+		}
+		k, err := parseKeySpec(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, keyBinding{k, action})
+	}
+	return keys, nil
+}
+
+// lookupKey returns the action bound to ev. A key with modifiers is
+// preferred to a key name which matches any modifiers.
+func lookupKey(keys []keyBinding, ev *tcell.EventKey) (string, bool) {
+	action, ok := "", false
+	for _, k := range keys {
+		if !k.match(ev) {
+			continue
+		}
+		if !k.anyMod {
+			return k.action, true
+		}
+		action, ok = k.action, true
+	}
+	return action, ok
+}
+
+// ---------------------------------------------
+// Set Key Map
+// ---------------------------------------------
+// SetKeyMap sets the key map of all panels. nil restores the usual keys.
+func SetKeyMap(km *KeyMap) error {
+	if km != nil {
+		if err := km.compile(); err != nil {
+			return err
+		}
+	}
+	taps.keyMap = km
+	return nil
+}
+
+// SetKeyMap sets the key map of the panel, instead of the global one.
+func (p *Panel) SetKeyMap(km *KeyMap) error {
+	if km != nil {
+		if err := km.compile(); err != nil {
+			return err
+		}
+	}
+	p.keyMap = km
+	p.keyMode = KEY_MODE_INSERT
+	return nil
+}
+
+func (p *Panel) getKeyMap() *KeyMap {
+	if p.keyMap != nil {
+		return p.keyMap
+	}
+	return taps.keyMap
+}
+
+// translateKey returns the key which does the action bound to ev, and the
+// name of the action. Exit keys are not translated.
+func (p *Panel) translateKey(i int, ev *tcell.EventKey) (tcell.Key, rune, tcell.ModMask, string) {
+	km := p.getKeyMap()
+	if km == nil || isExitKey(p.ExitKey, ev) || isExitKey(p.Field[i].ExitKey, ev) {
+		return ev.Key(), ev.Rune(), ev.Modifiers(), ""
+	}
+
+	keys := km.keys
+	if p.keyMode == KEY_MODE_NORMAL && len(km.normal) > 0 {
+		keys = km.normal
+	}
+	action, ok := lookupKey(keys, ev)
+	if !ok {
+		if p.keyMode == KEY_MODE_NORMAL && len(km.normal) > 0 && ev.Key() == tcell.KeyRune {
+			return tcell.KeyNUL, 0, tcell.ModNone, ACTION_NONE
+		}
+		return ev.Key(), ev.Rune(), ev.Modifiers(), ""
+	}
+
+	a := keyActions[action]
+	if len(km.normal) > 0 && a.mode != "" {
+		p.keyMode = a.mode
+	}
+	if a.key == tcell.KeyNUL && !isPanelAction(action) {
+		return tcell.KeyNUL, 0, tcell.ModNone, ACTION_NONE
+	}
+	return a.key, a.r, a.mod, action
+}
+
+// isPanelAction reports whether the action is done by the key of the
+// panel, e.g. UndoKey, instead of a key.
+func isPanelAction(action string) bool {
+	switch action {
+	case ACTION_UNDO, ACTION_REDO, ACTION_YANK, ACTION_YANK_POP:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+func TestKeyMapPresets(t *testing.T) {
+	for _, km := range []*KeyMap{NewEmacsKeyMap(), NewCUAKeyMap(), NewViKeyMap()} {
+		if err := km.compile(); err != nil {
+			t.Errorf("%s: %v", km.Name, err)
+		}
+	}
+}
+
+func TestCtrlKeyNames(t *testing.T) {
+	for s, key := range map[string]tcell.Key{
+		"Ctrl-H":    tcell.KeyBackspace,
+		"Ctrl-I":    tcell.KeyTab,
+		"Ctrl-M":    tcell.KeyEnter,
+		"Ctrl-[":    tcell.KeyEscape,
+		"Backspace": tcell.KeyBackspace,
+	} {
+		k, err := parseKeySpec(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if k.key != key {
+			t.Errorf("%s = %v, want %v", s, k.key, key)
+		}
+	}
+}
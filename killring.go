@@ -0,0 +1,141 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"time"
+)
+
+const (
+	KILL_RING_MAX     = 30
+	CLIPBOARD_TIMEOUT = 200 * time.Millisecond
+)
+
+// yankState is the text inserted by the last yank, for yank-pop. count
+// does not include the literals of Picture.
+type yankState struct {
+	focus int
+	count int
+}
+
+// clipboardTimeout wakes up Read when the terminal does not answer the
+// clipboard request.
+type clipboardTimeout struct {
+	y *yankState
+}
+
+// ---------------------------------------------
+// Kill ring
+// ---------------------------------------------
+// EnableClipboard copies the killed text to the system clipboard, and
+// yanks from it, by OSC 52. The terminal must support OSC 52.
+func EnableClipboard(flag bool) {
+	taps.clipboard = flag
+}
+
+func pushKill(s string) {
+	if s == "" {
+		return
+	}
+	taps.killRing = append(taps.killRing, s)
+	if len(taps.killRing) > KILL_RING_MAX {
+		taps.killRing = taps.killRing[1:]
+	}
+	taps.yankIndex = len(taps.killRing) - 1
+	if taps.clipboard && taps.screen != nil {
+		taps.screen.SetClipboard([]byte(s))
+	}
+}
+
+// GetKillRing returns the killed texts, the latest last.
+func GetKillRing() []string {
+	return append([]string(nil), taps.killRing...)
+}
+
+// ---------------------------------------------
+// Yank
+// ---------------------------------------------
+func (p *Panel) isYankKey(ev *tcell.EventKey) bool {
+	if len(p.YankKey) == 0 {
+		return ev.Key() == tcell.KeyCtrlV
+	}
+	return isExitKey(p.YankKey, ev)
+}
+
+func (p *Panel) isYankPopKey(ev *tcell.EventKey) bool {
+	if len(p.YankPopKey) == 0 {
+		return ev.Key() == tcell.KeyRune && ev.Rune() == 'y' && ev.Modifiers()&tcell.ModAlt != 0
+	}
+	return isExitKey(p.YankPopKey, ev)
+}
+
+// yank inserts s at the cursor. Line breaks are inserted as spaces.
+func (p *Panel) yank(i int, s string) {
+	f := p.Field[i]
+	n := f.editableCount(0, len(f.RData))
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	for _, r := range s {
+		p.input_data(i, r)
+	}
+	p.lastYank = &yankState{focus: i, count: f.editableCount(0, len(f.RData)) - n}
+}
+
+// requestYank yanks the latest kill, or the system clipboard when it is
+// enabled. The clipboard is read asynchronously.
+func (p *Panel) requestYank(i int) {
+	if taps.clipboard && taps.screen != nil {
+		y := &yankState{focus: i}
+		p.pendingYank = y
+		taps.screen.GetClipboard()
+		time.AfterFunc(CLIPBOARD_TIMEOUT, func() {
+			taps.screen.PostEvent(tcell.NewEventInterrupt(clipboardTimeout{y}))
+		})
+		return
+	}
+	p.yankKill(i)
+}
+
+func (p *Panel) yankKill(i int) {
+	if len(taps.killRing) == 0 {
+		return
+	}
+	taps.yankIndex = len(taps.killRing) - 1
+	u := p.getUndoState(i)
+	p.yank(i, taps.killRing[taps.yankIndex])
+	p.pushUndo(u, tcell.KeyCtrlV)
+}
+
+// doClipboard yanks the text of the clipboard to the pending field.
+// data is nil when the terminal did not answer.
+func (p *Panel) doClipboard(data []byte) {
+	y := p.pendingYank
+	if y == nil {
+		return
+	}
+	p.pendingYank = nil
+	s := string(data)
+	if s != "" && (len(taps.killRing) == 0 || taps.killRing[len(taps.killRing)-1] != s) {
+		taps.killRing = append(taps.killRing, s)
+		if len(taps.killRing) > KILL_RING_MAX {
+			taps.killRing = taps.killRing[1:]
+		}
+	}
+	p.yankKill(y.focus)
+}
+
+// yankPop replaces the text of the last yank with the previous kill.
+func (p *Panel) yankPop(i int, last *yankState) {
+	if last == nil || last.focus != i || len(taps.killRing) == 0 {
+		return
+	}
+	u := p.getUndoState(i)
+	for k := 0; k < last.count; k++ {
+		p.input_bs(i)
+	}
+	taps.yankIndex--
+	if taps.yankIndex < 0 {
+		taps.yankIndex = len(taps.killRing) - 1
+	}
+	p.yank(i, taps.killRing[taps.yankIndex])
+	p.pushUndo(u, tcell.KeyCtrlV)
+}
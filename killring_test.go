@@ -0,0 +1,78 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+const killRingDoc = `
+ExitKey = ["F3"]
+
+[[Field]]
+Name = "E"
+X = 1
+Y = 1
+FieldLen = 20
+Style = "edit, edit_focus"
+FieldType = "edit"
+`
+
+// readKeys reads the panel with the keys; a string is typed.
+func readKeys(s tcell.SimulationScreen, p *Panel, keys ...any) {
+	go func() {
+		for _, k := range keys {
+			switch k := k.(type) {
+			case string:
+				for _, r := range k {
+					s.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+				}
+			case *tcell.EventKey:
+				s.InjectKey(k.Key(), k.Rune(), k.Modifiers())
+			}
+		}
+		s.InjectKey(tcell.KeyF3, 0, tcell.ModNone)
+	}()
+	p.Read()
+}
+
+func TestYankPop(t *testing.T) {
+	for _, yankKey := range []string{"", "Ctrl-Y"} {
+		s := newTestScreen(t)
+		taps.killRing = nil
+		p := NewPanel(killRingDoc, testStyleMatrix, "")
+		yank := tcell.NewEventKey(tcell.KeyCtrlV, 0, tcell.ModNone)
+		if yankKey != "" {
+			p.YankKey = []string{yankKey}
+			yank = tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModNone)
+		}
+		home := tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModNone)
+		kill := tcell.NewEventKey(tcell.KeyCtrlK, 0, tcell.ModNone)
+		pop := tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModAlt)
+
+		readKeys(s, p, "first", home, kill, "second", home, kill, yank)
+		if got := p.Get("E"); got != "second" {
+			t.Errorf("%q: yank = %q, want second", yankKey, got)
+		}
+		readKeys(s, p, yank, pop)
+		if got := p.Get("E"); got != "secondfirst" {
+			t.Errorf("%q: yank-pop = %q, want secondfirst", yankKey, got)
+		}
+	}
+}
+
+func TestEmacsYank(t *testing.T) {
+	s := newTestScreen(t)
+	taps.killRing = nil
+	p := NewPanel(killRingDoc, testStyleMatrix, "")
+	if err := p.SetKeyMap(NewEmacsKeyMap()); err != nil {
+		t.Fatal(err)
+	}
+	readKeys(s, p, "abc",
+		tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModNone),
+		tcell.NewEventKey(tcell.KeyCtrlK, 0, tcell.ModNone),
+		tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModNone),
+		tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModNone))
+	if got := p.Get("E"); got != "abcabc" {
+		t.Errorf("Ctrl-Y in the emacs key map = %q, want abcabc", got)
+	}
+}
@@ -0,0 +1,136 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+)
+
+// ---------------------------------------------
+// Page
+// ---------------------------------------------
+// doPage moves by Ctrl-Home, Ctrl-End, PgUp and PgDn in list and grid
+// fields. Home and End without Ctrl are done by doEdit and doList.
+func (p *Panel) doPage(i int, cKey tcell.Key, mod tcell.ModMask) (bool, int) {
+	f := p.Field[i]
+	ctrl := mod&tcell.ModCtrl != 0
+	if (cKey == tcell.KeyHome || cKey == tcell.KeyEnd) && !ctrl {
+		return false, i
+	}
+
+	if isListMode(f) {
+		switch cKey {
+		case tcell.KeyHome:
+			return true, p.goListEdge(i, false)
+		case tcell.KeyEnd:
+			return true, p.goListEdge(i, true)
+		case tcell.KeyPgUp:
+			return true, p.pageList(i, -1)
+		case tcell.KeyPgDn:
+			return true, p.pageList(i, 1)
+		}
+		return false, i
+	}
+
+	if f.gridName != "" {
+		name := strings.Split(f.Name, GRID_SEP)[0]
+		col, _ := getGridPos(f.Name)
+		cols, rows := p.getGridSize(name)
+		switch cKey {
+		case tcell.KeyHome:
+			return true, p.moveFocus(i, p.GetGridFieldNumber(name, 0, 0))
+		case tcell.KeyEnd:
+			return true, p.moveFocus(i, p.GetGridFieldNumber(name, cols-1, rows-1))
+		case tcell.KeyPgUp:
+			return true, p.moveFocus(i, p.GetGridFieldNumber(name, col, 0))
+		case tcell.KeyPgDn:
+			return true, p.moveFocus(i, p.GetGridFieldNumber(name, col, rows-1))
+		}
+	}
+	return false, i
+}
+
+// scrollList changes listStart of the list s by n rows, and reports
+// whether the list has been scrolled.
+func (p *Panel) scrollList(s *DataField, n int) bool {
+	_, cnt := p.getLastList(s.Name)
+	start := s.listStart + n
+	if start > getListDataLen(s)-cnt {
+		start = getListDataLen(s) - cnt
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start == s.listStart {
+		return false
+	}
+	p.updateList(s.Name)
+	s.listStart = start
+	p.SayListData(s.Name)
+	return true
+}
+
+// pageList scrolls the list by the rows shown, and keeps the focus on the
+// same row of the screen. At the top or the bottom of the list, the focus
+// goes to the first or the last row.
+func (p *Panel) pageList(i, dir int) int {
+	s := p.getFirstList(p.Field[i].Name)
+	_, k := p.getListCountUntil(p.Field[i].Name)
+	_, cnt := p.getLastList(s.Name)
+	if !p.scrollList(s, dir*cnt) {
+		k = 0
+		if dir > 0 {
+			k = cnt - 1
+		}
+	}
+	return p.moveFocus(i, p.getNthEnabledList(s.Name, k))
+}
+
+// goListEdge goes to the first or the last row of the list.
+func (p *Panel) goListEdge(i int, last bool) int {
+	s := p.getFirstList(p.Field[i].Name)
+	if last {
+		p.scrollList(s, getListDataLen(s))
+	} else {
+		p.scrollList(s, -s.listStart)
+	}
+	_, cnt := p.getLastList(s.Name)
+	k := 0
+	if last {
+		k = cnt - 1
+	}
+	j := p.moveFocus(i, p.getNthEnabledList(s.Name, k))
+	p.Field[j].goFirstLinePos()
+	p.Field[j].Say()
+	return j
+}
+
+// getNthEnabledList returns the number of the k-th row shown in the list,
+// or the last row if the list has less rows.
+func (p *Panel) getNthEnabledList(n string, k int) int {
+	name := strings.Split(n, LIST_SEP)[0] + LIST_SEP
+	last := INVALID_KEY
+	for j, f := range p.Field {
+		if strings.HasPrefix(f.Name, name) && !isDisabled(f) {
+			last = j
+			if k <= 0 {
+				break
+			}
+			k--
+		}
+	}
+	return last
+}
+
+// moveFocus moves the focus from field i to field j.
+func (p *Panel) moveFocus(i, j int) int {
+	if j == INVALID_KEY || isDisabled(p.Field[j]) {
+		return i
+	}
+	if i != j {
+		SetNormalStyle(p.Field[i])
+		p.Field[i].Say()
+	}
+	SetFocusedStyle(p.Field[j])
+	p.Field[j].Say()
+	return j
+}
@@ -0,0 +1,62 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// The indicator written to ModeField.
+const (
+	MODE_INSERT    = "INS"
+	MODE_OVERWRITE = "OVR"
+)
+
+// ---------------------------------------------
+// Insert / Overwrite
+// ---------------------------------------------
+// SetOverwrite sets the overwrite mode of all panels. The Insert key
+// toggles it. The cursor is a bar in the insert mode, and a block in the
+// overwrite mode.
+func SetOverwrite(flag bool) {
+	taps.overwrite = flag
+	if taps.screen == nil {
+		return
+	}
+	if flag {
+		taps.screen.SetCursorStyle(tcell.CursorStyleSteadyBlock)
+	} else {
+		taps.screen.SetCursorStyle(tcell.CursorStyleSteadyBar)
+	}
+}
+
+func IsOverwrite() bool {
+	return taps.overwrite
+}
+
+func (p *Panel) toggleOverwrite() {
+	SetOverwrite(!taps.overwrite)
+	p.sayMode()
+}
+
+// sayMode writes the mode to ModeField, if the panel has it.
+func (p *Panel) sayMode() {
+	f := p.GetDataField(p.ModeField)
+	if f == nil {
+		return
+	}
+	if taps.overwrite {
+		p.sayMessage(f, MODE_OVERWRITE)
+	} else {
+		p.sayMessage(f, MODE_INSERT)
+	}
+}
+
+// overwriteData replaces the data at the cursor with r. A double-width
+// rune replaces two columns, so "ab" is replaced by one wide rune.
+func (f *DataField) overwriteData(r rune) {
+	end := f.hDataPos
+	for w := 0; end < len(f.RData) && w < runewidth.RuneWidth(r); end++ {
+		w += runewidth.RuneWidth(f.RData[end])
+	}
+	f.RData = append(f.RData[:f.hDataPos], append([]rune{r}, f.RData[end:]...)...)
+}
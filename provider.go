@@ -0,0 +1,89 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+)
+
+// ListProvider gives the rows of a list field on demand, instead of
+// the data stored by StoreList.
+type ListProvider interface {
+	Len() int
+	Row(i int) string
+}
+
+// ListFetcher is implemented by the provider which loads the rows in the
+// background. Fetch is called with the rows to be shown. When they are
+// loaded, the provider calls done, from any goroutine, to redraw the list.
+// Until then, Row may return "".
+type ListFetcher interface {
+	Fetch(start, count int, done func())
+}
+
+// listEvent wakes up Read so that the list is redrawn.
+type listEvent struct {
+	p    *Panel
+	name string
+}
+
+// ---------------------------------------------
+// List provider
+// ---------------------------------------------
+// SetListProvider makes the list field n read the rows from provider.
+// The list is read-only while the provider is set. StoreList or a nil
+// provider puts the list back to the stored data and the mode.
+func (p *Panel) SetListProvider(n string, provider ListProvider) {
+	f := p.getFirstList(n)
+	if f == nil {
+		return
+	}
+	attached := f.provider != nil
+	f.provider = provider
+	f.listStart = 0
+	// The stored data is kept under the provider.
+	if provider == nil && f.listData == nil {
+		f.setListData(f.storedList)
+	}
+
+	name := strings.Split(f.Name, LIST_SEP)[0] + LIST_SEP
+	for _, x := range p.Field {
+		if strings.HasPrefix(x.Name, name) {
+			if provider != nil {
+				if !attached {
+					x.savedBrowse = isBrowseMode(x)
+				}
+				x.BrowseMode()
+			} else if attached && !x.savedBrowse {
+				x.EditMode()
+			}
+		}
+	}
+}
+
+func (f *DataField) getListRow(k int) ListField {
+	if f.provider != nil {
+		return ListField{data: f.provider.Row(k)}
+	}
+	return f.listData[k]
+}
+
+func (f *DataField) getProviderData() []string {
+	listData := make([]string, f.provider.Len())
+	for k := range listData {
+		listData[k] = f.provider.Row(k)
+	}
+	return listData
+}
+
+func (p *Panel) fetchList(f *DataField, start, count int) {
+	fetcher, ok := f.provider.(ListFetcher)
+	if !ok {
+		return
+	}
+	name := f.Name
+	fetcher.Fetch(start, count, func() {
+		if taps.screen != nil {
+			taps.screen.PostEvent(tcell.NewEventInterrupt(listEvent{p, name}))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package taps
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const providerDoc = `
+[[Field]]
+Name = "L"
+X = 1
+Y = 1
+Rows = 3
+FieldLen = 10
+Style = "edit, edit_focus"
+FieldType = "edit"
+`
+
+type testProvider int
+
+func (n testProvider) Len() int         { return int(n) }
+func (n testProvider) Row(i int) string { return fmt.Sprintf("row%d", i) }
+
+func TestListProviderRoundTrip(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(providerDoc, testStyleMatrix, "")
+	stored := []string{"a", "b", "c", "d"}
+	p.StoreList(stored, "L")
+
+	p.SetListProvider("L", testProvider(100))
+	if got := p.GetList("L"); len(got) != 100 || got[99] != "row99" {
+		t.Fatalf("GetList with provider = %d rows", len(got))
+	}
+
+	p.SetListProvider("L", nil)
+	if got := p.GetList("L"); !reflect.DeepEqual(got, stored) {
+		t.Errorf("GetList after nil provider = %v, want %v", got, stored)
+	}
+}
+
+func TestListProviderKeepsMode(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(providerDoc, testStyleMatrix, "")
+	p.StoreList([]string{"a", "b"}, "L")
+	p.SetBrowseMode("L", true)
+
+	p.SetListProvider("L", testProvider(10))
+	p.SetListProvider("L", nil)
+	if !isBrowseMode(p.getFirstList("L")) {
+		t.Error("the list in browse mode is editable after nil provider")
+	}
+
+	p.SetBrowseMode("L", false)
+	p.SetListProvider("L", testProvider(10))
+	p.StoreList([]string{"c"}, "L")
+	if isBrowseMode(p.getFirstList("L")) {
+		t.Error("the list is read-only after StoreList")
+	}
+}
@@ -0,0 +1,197 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---------------------------------------------
+// Selection style
+// ---------------------------------------------
+// setSelectionStyle sets the style of the selected text. Without
+// SelectionStyle, the focused style is reversed.
+func (p *Panel) setSelectionStyle() {
+	for _, f := range p.Field {
+		if p.SelectionStyle != "" {
+			f.selectionStyle, _ = getStyle(p.SelectionStyle, p.styleMatrix)
+		} else {
+			f.selectionStyle = f.focusedStyle.Reverse(true)
+		}
+	}
+}
+
+// ---------------------------------------------
+// Selection
+// ---------------------------------------------
+// getSelection returns the selected range of the data.
+func (f *DataField) getSelection() (int, int, bool) {
+	if !f.selecting {
+		return 0, 0, false
+	}
+	from, to := f.selAnchor, f.hDataPos
+	if from > to {
+		from, to = to, from
+	}
+	if to > len(f.RData) {
+		to = len(f.RData)
+	}
+	if from >= to {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+func (f *DataField) isSelected(pos int) bool {
+	from, to, ok := f.getSelection()
+	return ok && pos >= from && pos < to
+}
+
+// clearSelection cancels the selection, and reports whether the field had it.
+func (f *DataField) clearSelection() bool {
+	_, _, ok := f.getSelection()
+	f.selecting = false
+	return ok
+}
+
+// SelectedText returns the text selected in the focused field.
+func (p *Panel) SelectedText() string {
+	if p.SelectFocus < 0 || p.SelectFocus >= len(p.Field) {
+		return ""
+	}
+	f := p.Field[p.SelectFocus]
+	from, to, ok := f.getSelection()
+	if !ok {
+		return ""
+	}
+	return string(f.RData[from:to])
+}
+
+// doSelection selects the text by Shift and the arrow keys, and cuts,
+// copies or deletes it.
+//
+//	Shift-Left, Shift-Right         a character
+//	Shift-Ctrl-Left, -Right         a word
+//	Shift-Home, Shift-End           to the start / the end of the line
+//	Shift-Up, Shift-Down            a wrapped line of list fields
+//	Ctrl-X, Ctrl-C                  cut, copy to the kill ring
+//	Delete, Backspace               delete the selected text
+//	a character                     replace the selected text
+//
+// Other keys cancel the selection.
+func (p *Panel) doSelection(i int, cKey tcell.Key, rKey rune, mod tcell.ModMask) bool {
+	f := p.Field[i]
+	if mod&tcell.ModShift != 0 && p.extendSelection(i, cKey, mod) {
+		return true
+	}
+
+	from, to, ok := f.getSelection()
+	if !ok {
+		f.selecting = false
+		return false
+	}
+	switch {
+	case cKey == tcell.KeyCtrlC:
+		pushKill(string(f.RData[from:to]))
+		return true
+	case cKey == tcell.KeyCtrlX:
+		pushKill(string(f.RData[from:to]))
+		p.deleteSelection(i)
+		return true
+	case cKey == tcell.KeyDelete || cKey == tcell.KeyBS || cKey == tcell.KeyBackspace2 || cKey == tcell.KeyCtrlH:
+		p.deleteSelection(i)
+		return true
+	case cKey == tcell.KeyRune && mod&tcell.ModAlt == 0:
+		u := p.getUndoState(i)
+		p.deleteTo(i, f.selAnchor)
+		f.selecting = false
+		p.input_data(i, rKey)
+		p.pushUndo(u, tcell.KeyCtrlW)
+		return true
+	}
+	f.clearSelection()
+	f.Say()
+	return false
+}
+
+// extendSelection moves the cursor with the anchor of the selection kept.
+func (p *Panel) extendSelection(i int, cKey tcell.Key, mod tcell.ModMask) bool {
+	f := p.Field[i]
+	pos := f.hDataPos
+	if !f.selecting {
+		f.selAnchor = pos
+	}
+	f.selecting = true
+	word := mod&tcell.ModCtrl != 0
+
+	switch cKey {
+	case tcell.KeyLeft:
+		if word {
+			p.moveTo(i, priorWordPos(f.RData, pos))
+		} else {
+			p.input_lt(i)
+		}
+	case tcell.KeyRight:
+		if word {
+			p.moveTo(i, nextWordPos(f.RData, pos))
+		} else {
+			p.input_rt(i)
+		}
+	case tcell.KeyHome:
+		p.moveTo(i, 0)
+	case tcell.KeyEnd:
+		p.moveTo(i, len(f.RData))
+	case tcell.KeyUp:
+		if !isListMode(f) || f.hCursorY == 0 {
+			return false
+		}
+		f.resetDataPos(f.hCursorX, f.hCursorY-1)
+	case tcell.KeyDown:
+		if !isListMode(f) || f.hCursorY >= p.additionalLines(f, string(f.RData)) {
+			return false
+		}
+		f.resetDataPos(f.hCursorX, f.hCursorY+1)
+	default:
+		if f.selAnchor == pos {
+			f.selecting = false
+		}
+		return false
+	}
+	f.Say()
+	return true
+}
+
+func (p *Panel) deleteSelection(i int) {
+	f := p.Field[i]
+	u := p.getUndoState(i)
+	p.deleteTo(i, f.selAnchor)
+	f.selecting = false
+	f.Say()
+	p.pushUndo(u, tcell.KeyCtrlW)
+}
+
+// ---------------------------------------------
+// Mouse drag
+// ---------------------------------------------
+// startDrag starts the selection by the mouse at the cursor of field i.
+func (p *Panel) startDrag(i int) {
+	p.dragging = true
+	p.dragField = i
+	p.Field[i].selAnchor = p.Field[i].hDataPos
+	p.Field[i].selecting = false
+}
+
+// dragTo extends the selection to the mouse position, while the button
+// is pressed in the field where the drag started. The motion over the
+// other fields is ignored until the button is released.
+func (p *Panel) dragTo(i int, ev *tcell.EventMouse) bool {
+	if !p.dragging || p.dragField != i {
+		return false
+	}
+	f := p.Field[i]
+	x, y := ev.Position()
+	if f.contains(x, y) {
+		f.resetDataPos(x-GetFieldX(f.X), y-GetFieldY(f.Y))
+		f.selecting = true
+		f.Say()
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+const selectionDoc = `
+ExitKey = ["F3"]
+
+[[Field]]
+Name = "E1"
+X = 1
+Y = 1
+FieldLen = 10
+Style = "edit, edit_focus"
+FieldType = "edit"
+Data = "abcdefgh"
+
+[[Field]]
+Name = "E2"
+X = 1
+Y = 2
+FieldLen = 10
+Style = "edit, edit_focus"
+FieldType = "edit"
+Data = "ijklmnop"
+`
+
+func TestDragStaysInField(t *testing.T) {
+	s := newTestScreen(t)
+	p := NewPanel(selectionDoc, testStyleMatrix, "")
+	p.Say()
+	e2 := p.GetDataField("E2")
+	e2.hDataPos = 5
+
+	go func() {
+		s.InjectMouse(2, 1, tcell.Button1, tcell.ModNone)
+		s.InjectMouse(5, 1, tcell.Button1, tcell.ModNone)
+		s.InjectMouse(3, 2, tcell.Button1, tcell.ModNone)
+		s.InjectMouse(3, 2, tcell.ButtonNone, tcell.ModNone)
+		s.InjectKey(tcell.KeyF3, 0, tcell.ModNone)
+	}()
+	p.Read()
+
+	if got := p.SelectedText(); got != "bcd" {
+		t.Errorf("SelectedText = %q, want bcd", got)
+	}
+	if e2.hDataPos != 5 {
+		t.Errorf("cursor of E2 moved to %d by the drag", e2.hDataPos)
+	}
+}
@@ -0,0 +1,197 @@
+package taps
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RowChange is a record of a grid changed since StoreRows.
+// Insert is true for a record beyond the rows stored by StoreRows.
+type RowChange struct {
+	Index    int
+	Insert   bool
+	Values   map[string]string
+	Original map[string]string
+}
+
+// Args returns the values of the columns in order, e.g. for the
+// parameters of db.Exec.
+func (c RowChange) Args(columns ...string) []any {
+	var args []any
+	for _, column := range columns {
+		args = append(args, c.Values[column])
+	}
+	return args
+}
+
+// ---------------------------------------------
+// Store Rows
+// ---------------------------------------------
+// StoreRows stores the result of a query to the grid or list field n.
+// For a grid, the columns are mapped to GridFields by name, and one row
+// is stored to one (col, row) in order of row and col. For a list, the
+// column named n, or the first column, is stored. rows is not closed.
+func (p *Panel) StoreRows(n string, rows *sql.Rows) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("taps: %s: %w", n, err)
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for k := range values {
+		dest[k] = &values[k]
+	}
+
+	if !p.isGridName(n) {
+		return p.storeListRows(n, rows, columns, values, dest)
+	}
+
+	names := p.getGridColumns(n)
+	cols, maxRows := p.getGridSize(names[0])
+	for k := 0; k < cols*maxRows; k++ {
+		for _, name := range names {
+			p.storeCellValue("", p.GetGridFieldName(name, k%cols, k/cols))
+		}
+	}
+
+	k := 0
+	for rows.Next() {
+		if k >= cols*maxRows {
+			return fmt.Errorf("taps: %s: more rows than %d cells", n, cols*maxRows)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("taps: %s: %w", n, err)
+		}
+		for j, column := range columns {
+			for _, name := range names {
+				if !strings.EqualFold(column, name) {
+					continue
+				}
+				if err := p.storeColumn(values[j], p.GetGridFieldName(name, k%cols, k/cols)); err != nil {
+					return err
+				}
+			}
+		}
+		k++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("taps: %s: %w", n, err)
+	}
+
+	if p.loadedRows == nil {
+		p.loadedRows = make(map[string]int)
+	}
+	p.loadedRows[n] = k
+	return nil
+}
+
+// StoreQuery runs the query on db and stores the result by StoreRows.
+func (p *Panel) StoreQuery(n string, db *sql.DB, query string, args ...any) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("taps: %s: %w", n, err)
+	}
+	defer rows.Close()
+	return p.StoreRows(n, rows)
+}
+
+func (p *Panel) storeListRows(n string, rows *sql.Rows, columns []string, values, dest []any) error {
+	f := p.getFirstList(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: not a list or grid field", n)
+	}
+	j := 0
+	for k, column := range columns {
+		if strings.EqualFold(column, n) {
+			j = k
+		}
+	}
+
+	listData := []string{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("taps: %s: %w", n, err)
+		}
+		s, err := f.formatColumn(values[j])
+		if err != nil {
+			return err
+		}
+		listData = append(listData, s)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("taps: %s: %w", n, err)
+	}
+	p.StoreList(listData, n)
+	return nil
+}
+
+func (p *Panel) storeColumn(v any, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		f = p.getFirstList(n)
+	}
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	s, err := f.formatColumn(v)
+	if err != nil {
+		return err
+	}
+	return p.storeCellValue(s, n)
+}
+
+// formatColumn formats the value scanned from the driver with Format of
+// the field.
+func (f *DataField) formatColumn(v any) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case []byte:
+		return string(x), nil
+	}
+	return f.formatValue(reflect.ValueOf(v))
+}
+
+// ---------------------------------------------
+// Row changes
+// ---------------------------------------------
+// RowChanges returns the records of the grid n modified by the user since
+// StoreRows, to make the parameters of UPDATE and INSERT.
+func (p *Panel) RowChanges(n string) ([]RowChange, error) {
+	if !p.isGridName(n) {
+		return nil, fmt.Errorf("taps: %s: not a grid field", n)
+	}
+	names := p.getGridColumns(n)
+	cols, rows := p.getGridSize(names[0])
+
+	var changes []RowChange
+	for k := 0; k < cols*rows; k++ {
+		c := RowChange{
+			Index:    k,
+			Insert:   k >= p.loadedRows[n],
+			Values:   make(map[string]string),
+			Original: make(map[string]string),
+		}
+		modified := false
+		for _, name := range names {
+			cell := p.GetGridFieldName(name, k%cols, k/cols)
+			if f := p.getFirstList(cell); f != nil {
+				modified = modified || f.modified
+				c.Values[name] = strings.Join(f.getListData(), "\n")
+				c.Original[name] = strings.Join(f.storedList, "\n")
+				continue
+			}
+			if f := p.GetDataField(cell); f != nil {
+				modified = modified || f.modified
+				c.Values[name] = string(f.RData)
+				c.Original[name] = string(f.stored)
+			}
+		}
+		if modified {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,185 @@
+package taps
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stubDriver is a database/sql driver of one table in memory.
+//
+//	SELECT ID, NAME, QTY           the columns of all rows
+//	UPDATE ?, ?, ?                 NAME, QTY, ID
+type stubDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+type stubConn struct{ d *stubDriver }
+
+type stubStmt struct {
+	d     *stubDriver
+	query string
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var stub = &stubDriver{}
+
+func init() {
+	sql.Register("tapsstub", stub)
+}
+
+func (d *stubDriver) Open(name string) (driver.Conn, error) { return &stubConn{d}, nil }
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) { return &stubStmt{c.d, query}, nil }
+func (c *stubConn) Close() error                              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error)                 { return nil, fmt.Errorf("stub: no transaction") }
+
+func (s *stubStmt) Close() error  { return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "UPDATE") || len(args) != 3 {
+		return nil, fmt.Errorf("stub: bad statement %q", s.query)
+	}
+	for _, r := range s.d.rows {
+		if fmt.Sprint(r[0]) == fmt.Sprint(args[2]) {
+			r[1], r[2] = args[0], args[1]
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	columns := strings.Split(strings.TrimPrefix(s.query, "SELECT "), ",")
+	var index []int
+	for k := range columns {
+		columns[k] = strings.TrimSpace(columns[k])
+		n := -1
+		for j, c := range s.d.columns {
+			if c == columns[k] {
+				n = j
+			}
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("stub: no such column: %s", columns[k])
+		}
+		index = append(index, n)
+	}
+	rows := &stubRows{columns: columns}
+	for _, r := range s.d.rows {
+		var row []driver.Value
+		for _, n := range index {
+			row = append(row, r[n])
+		}
+		rows.rows = append(rows.rows, row)
+	}
+	return rows, nil
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+const sqlDoc = `
+[[Field]]
+Name = "G"
+X = 1
+Y = 1
+Rows = 3
+FieldLen = 30
+  [[Field.GridFields]]
+  Name = "ID"
+  X = 1
+  Y = 1
+  FieldLen = 4
+  Style = "label"
+  FieldType = "label"
+  [[Field.GridFields]]
+  Name = "NAME"
+  X = 6
+  Y = 1
+  FieldLen = 10
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+  [[Field.GridFields]]
+  Name = "QTY"
+  X = 17
+  Y = 1
+  FieldLen = 5
+  Style = "edit, edit_focus"
+  FieldType = "edit"
+  Attr = "N"
+`
+
+func TestStoreQuery(t *testing.T) {
+	newTestScreen(t)
+	stub.columns = []string{"ID", "NAME", "QTY"}
+	stub.rows = [][]driver.Value{
+		{int64(1), "apple", int64(3)},
+		{int64(2), nil, nil},
+	}
+	db, err := sql.Open("tapsstub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	p := NewPanel(sqlDoc, testStyleMatrix, "")
+	if err := p.StoreQuery("G", db, "SELECT ID, NAME, QTY"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Get(p.GetGridFieldName("NAME", 0, 0)); got != "apple" {
+		t.Errorf("NAME(0, 0) = %q, want apple", got)
+	}
+	if got := p.Get(p.GetGridFieldName("QTY", 0, 0)); got != "3" {
+		t.Errorf("QTY(0, 0) = %q, want 3", got)
+	}
+	// NULL is stored as "".
+	if got := p.Get(p.GetGridFieldName("NAME", 0, 1)); got != "" {
+		t.Errorf("NAME(0, 1) = %q, want \"\"", got)
+	}
+
+	// Save the edited record and load it again.
+	n := p.GetGridFieldName("NAME", 0, 1)
+	p.Store("banana", n)
+	p.setModified(p.GetFieldNumber(n))
+	changes, err := p.RowChanges("G")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Index != 1 || changes[0].Insert {
+		t.Fatalf("RowChanges = %+v", changes)
+	}
+	if _, err := db.Exec("UPDATE ?, ?, ?", append(changes[0].Args("NAME", "QTY"), changes[0].Original["ID"])...); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StoreQuery("G", db, "SELECT ID, NAME, QTY"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Get(p.GetGridFieldName("NAME", 0, 1)); got != "banana" {
+		t.Errorf("NAME(0, 1) after save = %q, want banana", got)
+	}
+	if changes, _ := p.RowChanges("G"); len(changes) != 0 {
+		t.Errorf("RowChanges after StoreQuery = %+v", changes)
+	}
+
+	if err := p.StoreQuery("G", db, "SELECT ID, PRICE"); err == nil || !strings.Contains(err.Error(), "PRICE") {
+		t.Errorf("StoreQuery of unknown column = %v", err)
+	}
+}
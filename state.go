@@ -0,0 +1,117 @@
+package taps
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type panelState struct {
+	SelectFocus int
+	Fields      []fieldState
+}
+
+type fieldState struct {
+	Name         string
+	Data         string
+	Stored       string
+	Disabled     bool
+	Browse       bool
+	Modified     bool
+	DataPos      int
+	StartDataPos int
+	CursorX      int
+	CursorY      int
+	ListStart    int         `json:",omitempty"`
+	List         []listState `json:",omitempty"`
+	StoredList   []string    `json:",omitempty"`
+}
+
+type listState struct {
+	Data         string
+	DataPos      int
+	StartDataPos int
+	CursorX      int
+	CursorY      int
+}
+
+// ---------------------------------------------
+// Marshal / Unmarshal State
+// ---------------------------------------------
+// MarshalState returns the state of the panel as JSON; the data, the list
+// data, the cursor positions, the focus and the enabled/browse flags.
+func (p *Panel) MarshalState() ([]byte, error) {
+	state := panelState{SelectFocus: p.SelectFocus}
+	for _, f := range p.Field {
+		if f.Name == "" {
+			continue
+		}
+		fs := fieldState{
+			Name:         f.Name,
+			Data:         string(f.RData),
+			Stored:       string(f.stored),
+			Disabled:     isDisabled(f),
+			Browse:       isBrowseMode(f),
+			Modified:     f.modified,
+			DataPos:      f.hDataPos,
+			StartDataPos: f.hStartDataPos,
+			CursorX:      f.hCursorX,
+			CursorY:      f.hCursorY,
+			ListStart:    f.listStart,
+			StoredList:   f.storedList,
+		}
+		for _, l := range f.listData {
+			fs.List = append(fs.List, listState{l.data, l.hDataPos, l.hStartDataPos, l.hCursorX, l.hCursorY})
+		}
+		state.Fields = append(state.Fields, fs)
+	}
+	return json.Marshal(state)
+}
+
+// UnmarshalState restores the state saved by MarshalState. Fields which
+// are no longer in the panel are ignored. Call Say to show the panel.
+func (p *Panel) UnmarshalState(data []byte) error {
+	var state panelState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("taps: UnmarshalState: %w", err)
+	}
+
+	for _, fs := range state.Fields {
+		f := p.GetDataField(fs.Name)
+		if f == nil {
+			continue
+		}
+		f.RData = []rune(fs.Data)
+		f.Data = fs.Data
+		f.stored = []rune(fs.Stored)
+		f.modified = fs.Modified
+		if fs.Disabled {
+			f.Disabled()
+		} else {
+			f.Enabled()
+		}
+		if fs.Browse {
+			f.BrowseMode()
+		} else {
+			f.EditMode()
+		}
+		f.hDataPos = fs.DataPos
+		f.hStartDataPos = fs.StartDataPos
+		f.hCursorX = fs.CursorX
+		f.hCursorY = fs.CursorY
+		f.listStart = fs.ListStart
+		f.storedList = fs.StoredList
+		f.listData = nil
+		for _, l := range fs.List {
+			f.listData = append(f.listData, ListField{l.Data, l.DataPos, l.StartDataPos, l.CursorX, l.CursorY})
+		}
+		if f.hDataPos > len(f.RData) {
+			f.hDataPos = len(f.RData)
+		}
+	}
+
+	if state.SelectFocus >= 0 && state.SelectFocus < len(p.Field) {
+		p.SelectFocus = state.SelectFocus
+	}
+	p.keepCursor = true
+	return nil
+}
@@ -5,8 +5,12 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
 	"github.com/pelletier/go-toml/v2"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 const (
@@ -14,6 +18,8 @@ const (
 	NORMAL_MODE = 0x00
 	LIST_MODE   = 0x01
 	BROWSE_MODE = 0x02
+	ERROR_MODE  = 0x04
+	FOCUS_MODE  = 0x08
 	DISABLED    = 0x80
 	INVALID_KEY = -1
 	EDIT        = "EDIT"
@@ -23,12 +29,38 @@ const (
 	GRID_SEP    = "_$#"
 )
 
-var taps = &Taps{}
+var taps = &Taps{fill: cell{r: ' '}}
 
 type Taps struct {
 	screen tcell.Screen
 	style  tcell.Style
 	err    error
+	// ------
+	cells   map[cellPos]cell
+	fill    cell
+	covered map[cellPos]bool
+	// ------
+	toastMu      sync.Mutex
+	toasts       []*toast
+	toastTimeout time.Duration
+	toastCorner  int
+	toastStyle   map[int]tcell.Style
+	// ------
+	killRing  []string
+	yankIndex int
+	clipboard bool
+	keyMap    *KeyMap
+	overwrite bool
+}
+
+type cellPos struct {
+	x, y int
+}
+
+type cell struct {
+	r     rune
+	bc    []rune
+	style tcell.Style
 }
 
 type Panel struct {
@@ -38,9 +70,42 @@ type Panel struct {
 	SelectFocus    int
 	Rect           bool
 	ExitKey        []string
+	ExitKeyLabel   map[string]string
+	CancelKey      []string
+	ErrorField     string
+	ErrorStyle     string
+	SummaryField   string
+	ModeField      string
+	SelectionStyle string
+	KeyBarX        int
+	KeyBarY        *int
+	KeyBarStyle    string
+	HelpKey        []string
+	HelpField      string
+	HelpStyle      string
+	UndoKey        []string
+	RedoKey        []string
+	YankKey        []string
+	YankPopKey     []string
 	styleMatrix    [][]string
 	doc            string
 	help           string
+	errorMsg       string
+	summary        []string
+	panelValidator func(*Panel) []FieldError
+	undoList       []*undoState
+	redoList       []*undoState
+	keepCursor     bool
+	loadedRows     map[string]int
+	lastYank       *yankState
+	pendingYank    *yankState
+	keyMap         *KeyMap
+	keyMode        string
+	lastKey        *tcell.EventKey
+	lastMouse      *tcell.EventMouse
+	dragging       bool
+	dragField      int
+	keyBar         []keyBarItem
 }
 
 type ListField struct {
@@ -61,8 +126,12 @@ type DataMain struct {
 	Attr           string
 	DataLen        int
 	Picture        string
+	Format         string
 	Rect           bool
 	ExitKey        []string
+	Help           string
+	TabIndex       int
+	FieldRule
 }
 
 type DataField struct {
@@ -76,8 +145,23 @@ type DataField struct {
 	// ------
 	normalStyle  tcell.Style
 	focusedStyle tcell.Style
+	errorStyle   tcell.Style
+	selectionStyle tcell.Style
+	regex        *regexp.Regexp
+	regexErr     error
+	validator    func(string) error
+	formatter    func(string) string
+	gridName     string
+	gridIndex    int
+	provider     ListProvider
+	savedBrowse  bool
 	listStart    int
 	listData      []ListField
+	stored       []rune
+	storedList   []string
+	modified     bool
+	selAnchor    int
+	selecting    bool
 	hMode         byte
 	hDataPos      int
 	hStartDataPos int
@@ -98,7 +182,7 @@ func Init() error {
 		}
 
 		//taps.screen.SetStyle(taps.style)
-		taps.screen.EnableMouse(tcell.MouseButtonEvents)
+		taps.screen.EnableMouse(tcell.MouseButtonEvents | tcell.MouseDragEvents)
 	}
 	return nil
 }
@@ -124,8 +208,33 @@ func checkXY(x, y int) bool {
 
 func SetContent(x int, y int, r rune, bc []rune, style tcell.Style) {
 	if checkXY(x, y) {
-		taps.screen.SetContent(x, y, r, bc, style)
+		pos := cellPos{x, y}
+		if taps.cells == nil {
+			taps.cells = make(map[cellPos]cell)
+		}
+		taps.cells[pos] = cell{r, bc, style}
+		if runewidth.RuneWidth(r) > 1 {
+			delete(taps.cells, cellPos{x + 1, y})
+		}
+		if !taps.covered[pos] {
+			taps.screen.SetContent(x, y, r, bc, style)
+		}
+	}
+}
+
+// restoreCell puts back the content last written by SetContent at (x, y).
+func restoreCell(x, y int) {
+	if l, ok := taps.cells[cellPos{x - 1, y}]; ok && runewidth.RuneWidth(l.r) > 1 {
+		if !taps.covered[cellPos{x - 1, y}] {
+			taps.screen.SetContent(x-1, y, l.r, l.bc, l.style)
+		}
+		return
+	}
+	c, ok := taps.cells[cellPos{x, y}]
+	if !ok {
+		c = taps.fill
 	}
+	taps.screen.SetContent(x, y, c.r, c.bc, c.style)
 }
 
 func Show() {
@@ -153,10 +262,18 @@ func ClrEol(j int) {
 
 func Clear() {
 	taps.screen.Clear()
+	taps.cells = nil
+	taps.fill = cell{' ', nil, tcell.StyleDefault}
+	taps.covered = nil
+	drawToasts()
 }
 
 func Fill(r rune, style tcell.Style) {
 	taps.screen.Fill(r, style)
+	taps.cells = nil
+	taps.fill = cell{r, nil, style}
+	taps.covered = nil
+	drawToasts()
 }
 
 func ConsoleOut(ss string, x, y int, style tcell.Style) {
@@ -307,6 +424,10 @@ func getStyle(styleString string, m [][]string) (tcell.Style, tcell.Style) {
 func (p *Panel) setGridFieldStyle(i int) int {
 	pos := i
 	gridFields := p.Field[pos].GridFields
+	gridName := p.Field[pos].Name
+	if gridName == "" {
+		gridName = gridFields[0].Name
+	}
 	
 	gridFieldLen := GetFieldX(p.Field[pos].FieldLen)
 	maxLen := 0
@@ -333,6 +454,7 @@ func (p *Panel) setGridFieldStyle(i int) int {
 	}
 	colSpaces := GetFieldY(p.Field[pos].ColSpaces)
 	rowSpaces := GetFieldY(p.Field[pos].RowSpaces)
+	tabIndex := p.Field[pos].TabIndex
 
 	rowWidth := 1
 	minY := 9999
@@ -372,13 +494,22 @@ func (p *Panel) setGridFieldStyle(i int) int {
 					s.Attr = gridFields[k].Attr
 					s.DataLen = gridFields[k].DataLen
 					s.Picture = gridFields[k].Picture
+					s.Format = gridFields[k].Format
 					s.ExitKey = gridFields[k].ExitKey
+					s.Help = gridFields[k].Help
+					s.TabIndex = gridFields[k].TabIndex
+					if s.TabIndex == 0 {
+						s.TabIndex = tabIndex
+					}
+					s.FieldRule = gridFields[k].FieldRule
 					s.FieldLen = gridFields[k].FieldLen
 
 					s.X = xpos + (gridFieldLen + colSpaces)*col
 					s.Y = ypos + fr +(rowWidth + rowSpaces)*row
 
 					s.Name = gridFields[k].Name + GRID_SEP + fmt.Sprintf("%03d:%03d", col, row)
+					s.gridName = gridName
+					s.gridIndex = k
 
 					if GetFieldY(gridFields[k].Rows) > 0{
 						s.hMode = LIST_MODE
@@ -440,7 +571,11 @@ func (p *Panel) setListFieldStyle(i int, s0 tcell.Style, s1 tcell.Style) int {
 		s.Attr = p.Field[pos].Attr
 		s.DataLen = p.Field[pos].DataLen
 		s.Picture = p.Field[pos].Picture
+		s.Format = p.Field[pos].Format
 		s.ExitKey = p.Field[pos].ExitKey
+		s.Help = p.Field[pos].Help
+		s.TabIndex = p.Field[pos].TabIndex
+		s.FieldRule = p.Field[pos].FieldRule
 		s.FieldLen = fieldLen
 
 		s.Name = name + LIST_SEP + fmt.Sprintf("%03d", fnum)
@@ -495,7 +630,8 @@ func (p *Panel) setFieldStyle(doc string, styleMatrix [][]string, help string) {
 			//@@@@
 			p.Field[i].X = p.Field[i].X + GetFieldX(p.StartX)
 			p.Field[i].Y = p.Field[i].Y + GetFieldY(p.StartY)
-			p.Field[i].RData = []rune(p.Field[i].Data)
+			p.Field[i].RData = p.Field[i].pictureData(p.Field[i].Data)
+			p.Field[i].stored = append([]rune(nil), p.Field[i].RData...)
 			p.Field[i].hMode = NORMAL_MODE
 			i++
 			continue
@@ -511,6 +647,8 @@ func (p *Panel) setFieldStyle(doc string, styleMatrix [][]string, help string) {
 		}
 
 	}
+	p.setFieldRule()
+	p.setSelectionStyle()
 }
 
 func (p *Panel) GetFieldStyle(style string) (tcell.Style, tcell.Style){
@@ -593,6 +731,13 @@ func isDisabled(f *DataField) bool {
 	return false
 }
 
+func isFocused(f *DataField) bool {
+	if f.hMode & FOCUS_MODE != 0x00 {
+		return true
+	}
+	return false
+}
+
 func isListMode(f *DataField) bool {
 	if f.hMode & LIST_MODE != 0x00 {
 		return true
@@ -716,8 +861,9 @@ func (f *DataField) writeField() {
 		return
 	}
 
+	data, _ := f.displayData()
 	x := 0
-	for i := 0; i < len(f.RData); i++ {
+	for i := 0; i < len(data); i++ {
 		if (x+GetFieldX(f.X) >= mx) || (f.FieldLen > 0 && x >= f.GetFieldLen()) {
 			//@@@@@
 			//if isListMode(f) && isLabel(f) && y < GetFieldY(f.Y)+GetFieldY(f.Rows) {
@@ -729,8 +875,8 @@ func (f *DataField) writeField() {
 			}
 		}
 
-		SetContent(x+GetFieldX(f.X), y, f.RData[i], nil, f.currentStyle)
-		x += runewidth.RuneWidth(f.RData[i])
+		SetContent(x+GetFieldX(f.X), y, data[i], nil, f.currentStyle)
+		x += runewidth.RuneWidth(data[i])
 	}
 	Show()
 }
@@ -743,8 +889,9 @@ func (f *DataField) writeEdit() {
 		return
 	}
 
+	data, start := f.displayData()
 	x := 0
-	for i := f.hStartDataPos; i < len(f.RData); i++ {
+	for i := start; i < len(data); i++ {
 		//@@@@ Zenkaku/Hankaku
 		if (x+GetFieldX(f.X) >= mx) || ((f.FieldLen > 0) && (x >= f.GetFieldLen())) {
 			if isListMode(f) && y < GetFieldY(f.Y)+GetFieldY(f.Rows) {
@@ -755,9 +902,22 @@ func (f *DataField) writeEdit() {
 			}
 		}
 
-		SetContent(x+GetFieldX(f.X), y, f.RData[i], nil, f.currentStyle)
-		x += runewidth.RuneWidth(f.RData[i])
+		style := f.currentStyle
+		if f.isSelected(i) {
+			style = f.selectionStyle
+		}
+		SetContent(x+GetFieldX(f.X), y, data[i], nil, style)
+		x += runewidth.RuneWidth(data[i])
+	}
+}
+
+// displayData returns the data to write and the start position.
+// The formatter is not applied while the edit field has the focus.
+func (f *DataField) displayData() ([]rune, int) {
+	if f.formatter == nil || (isEdit(f) && isFocused(f)) {
+		return f.RData, f.hStartDataPos
 	}
+	return []rune(f.formatter(string(f.RData))), 0
 }
 
 // ---------------------------------------------
@@ -833,6 +993,10 @@ func (p *Panel) ModifyFieldLen(name string, fieldLen int) {
 }
 
 func (p *Panel) SayListData(n string) {
+	p.sayListData(n, true)
+}
+
+func (p *Panel) sayListData(n string, fetch bool) {
 	s := p.getFirstList(n)
 	pos := p.GetFieldNumber(s.Name)
 	start := p.Field[pos].listStart
@@ -846,26 +1010,30 @@ func (p *Panel) SayListData(n string) {
 	if isDisabled(p.Field[pos]){
 		return
 	}
-	if len(s.listData) == 0{
+	if getListDataLen(s) == 0{
 		return
 	}
 	//
+	if fetch {
+		p.fetchList(s, start, listLen)
+	}
 
 	for i < pos+listLen {
 		if lines == 0 {
 			p.Field[i].Enabled()
 
-			if dataPos < len(s.listData)-start {
-				p.Store(s.listData[dataPos+start].data, p.Field[i].Name)
-				p.Field[i].hDataPos = s.listData[dataPos+start].hDataPos
-				p.Field[i].hStartDataPos = s.listData[dataPos+start].hStartDataPos
-				p.Field[i].hCursorX = s.listData[dataPos+start].hCursorX
-				p.Field[i].hCursorY = s.listData[dataPos+start].hCursorY
+			if dataPos < getListDataLen(s)-start {
+				l := s.getListRow(dataPos + start)
+				p.Store(l.data, p.Field[i].Name)
+				p.Field[i].hDataPos = l.hDataPos
+				p.Field[i].hStartDataPos = l.hStartDataPos
+				p.Field[i].hCursorX = l.hCursorX
+				p.Field[i].hCursorY = l.hCursorY
 
 				//@@@@
 				//if !isSelect(p.Field[i]) {
 				if isEdit(p.Field[i]) {
-					lines = p.additionalLines(p.Field[i], s.listData[dataPos+start].data)
+					lines = p.additionalLines(p.Field[i], l.data)
 				}
 			} else {
 				p.Store("", p.Field[i].Name)
@@ -905,16 +1073,21 @@ func (p *Panel) Say() {
 			i += next
 		} else {
 			if !isDisabled(p.Field[i]) {
-				p.Field[i].hDataPos = 0
-				p.Field[i].hStartDataPos = 0
-				p.Field[i].hCursorX = 0
-				p.Field[i].hCursorY = 0
+				// The cursor restored by UnmarshalState is kept.
+				if !p.keepCursor {
+					p.Field[i].hDataPos = 0
+					p.Field[i].hStartDataPos = 0
+					p.Field[i].hCursorX = 0
+					p.Field[i].hCursorY = 0
+				}
 				p.Field[i].Say()
 			}
 			i++
 		}
 
 	}
+	p.keepCursor = false
+	p.sayKeyBar()
 	Show()
 }
 
@@ -1021,7 +1194,19 @@ func (f *DataField) getCursorPosY() int {
 // ============================================
 func (p *Panel) input_del(i int) {
 	if p.Field[i].hDataPos < len(p.Field[i].RData) {
-		p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos], p.Field[i].RData[p.Field[i].hDataPos+1:]...)
+		if len(p.Field[i].Picture) > 0 {
+			if !p.Field[i].deletePicture(p.Field[i].rawPicturePos(p.Field[i].hDataPos)) {
+				return
+			}
+			if p.Field[i].hDataPos > len(p.Field[i].RData) {
+				p.Field[i].hDataPos = len(p.Field[i].RData)
+			}
+			p.Field[i].setStartDataPos()
+			p.Field[i].setCursorPos()
+		} else {
+			p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos], p.Field[i].RData[p.Field[i].hDataPos+1:]...)
+		}
+		p.setModified(i)
 		if isListMode(p.Field[i]) {
 			p.updateList(p.Field[i].Name)
 			p.SayListData(p.Field[i].Name)
@@ -1034,8 +1219,20 @@ func (p *Panel) input_del(i int) {
 
 func (p *Panel) input_bs(i int) {
 	if p.Field[i].hDataPos > 0 {
-		p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos-1], p.Field[i].RData[p.Field[i].hDataPos:]...)
-		p.Field[i].hDataPos--
+		if len(p.Field[i].Picture) > 0 {
+			pos := p.Field[i].rawPicturePos(p.Field[i].hDataPos)
+			if !p.Field[i].deletePicture(pos - 1) {
+				return
+			}
+			p.Field[i].hDataPos = p.Field[i].pictureSlots()[pos-1]
+			if p.Field[i].hDataPos > len(p.Field[i].RData) {
+				p.Field[i].hDataPos = len(p.Field[i].RData)
+			}
+		} else {
+			p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos-1], p.Field[i].RData[p.Field[i].hDataPos:]...)
+			p.Field[i].hDataPos--
+		}
+		p.setModified(i)
 		p.Field[i].setStartDataPos()
 		p.Field[i].setCursorPos()
 
@@ -1066,6 +1263,7 @@ func (p *Panel) input_rt(i int) {
 
 	if p.Field[i].hDataPos < len(p.Field[i].RData) {
 		p.Field[i].hDataPos++
+		p.Field[i].skipPictureLiteral()
 	}
 	p.Field[i].setStartDataPos()
 	p.Field[i].setCursorPos()
@@ -1074,7 +1272,16 @@ func (p *Panel) input_rt(i int) {
 }
 
 func (p *Panel) input_lt(i int) {
-	if p.Field[i].hCursorX == 0 {
+	if len(p.Field[i].Picture) > 0 && !isListMode(p.Field[i]) {
+		if p.Field[i].hDataPos > 0 {
+			p.Field[i].hDataPos--
+			for p.Field[i].hDataPos > 0 && p.Field[i].isPictureLiteral(p.Field[i].hDataPos) {
+				p.Field[i].hDataPos--
+			}
+			p.Field[i].setStartDataPos()
+			p.Field[i].setCursorPos()
+		}
+	} else if p.Field[i].hCursorX == 0 {
 		if p.Field[i].hCursorY > 0 {
 			p.Field[i].hDataPos--
 			p.Field[i].setCursorPos()
@@ -1122,47 +1329,223 @@ func (f *DataField) isNumeric(r rune) bool {
 	return false
 }
 
-func (f *DataField) checkPicture() bool {
-	hLen := len(f.Picture)
-	if hLen == 0 {
-		return true
+// ---------------------------------------------
+// Picture
+// ---------------------------------------------
+// Picture characters
+//   9 : digit
+//   A : letter
+//   X : any character
+//   ! : any character, converted to upper case
+// Other characters are literals, inserted automatically.
+func isPictureSlot(c rune) bool {
+	return c == '9' || c == 'A' || c == 'X' || c == '!'
+}
+
+func matchPicture(c rune, r rune) (rune, bool) {
+	switch c {
+	case '9':
+		return r, '0' <= r && r <= '9'
+	case 'A':
+		return r, unicode.IsLetter(r)
+	case 'X':
+		return r, true
+	case '!':
+		return unicode.ToUpper(r), true
 	}
-	if f.DataLen < hLen {
-		if f.Picture[f.hDataPos] == '9' {
-			return f.isNumeric(f.RData[f.hDataPos])
+	return r, false
+}
+
+func (f *DataField) pictureSlots() []int {
+	var slots []int
+	for i, c := range []rune(f.Picture) {
+		if isPictureSlot(c) {
+			slots = append(slots, i)
 		}
 	}
-	return true
+	return slots
 }
 
-func (p *Panel) input_data(i int, r rune) {
-	// Check data length
-	if p.Field[i].DataLen > 0 && len(p.Field[i].RData) == p.Field[i].DataLen {
-		return
+func (f *DataField) isPictureLiteral(pos int) bool {
+	pic := []rune(f.Picture)
+	return pos >= 0 && pos < len(pic) && !isPictureSlot(pic[pos])
+}
+
+// Number of slots before pos
+func (f *DataField) rawPicturePos(pos int) int {
+	cnt := 0
+	for _, k := range f.pictureSlots() {
+		if k >= pos {
+			break
+		}
+		cnt++
+	}
+	return cnt
+}
+
+// Number of characters between from and to, without the literals
+func (f *DataField) editableCount(from, to int) int {
+	if len(f.Picture) > 0 {
+		return f.rawPicturePos(to) - f.rawPicturePos(from)
 	}
+	return to - from
+}
 
-	// Numeric check
-	if p.Field[i].Attr == "N" || p.Field[i].Attr == "n" {
-		if p.Field[i].isNumeric(r) == false {
-			return
+func (f *DataField) rawPicture(data []rune) []rune {
+	pic := []rune(f.Picture)
+	var raw []rune
+	for i, r := range data {
+		if i < len(pic) && isPictureSlot(pic[i]) {
+			raw = append(raw, r)
 		}
 	}
+	return raw
+}
 
-	// Check picture string
-	p.Field[i].checkPicture()
+func (f *DataField) formatPicture(raw []rune) []rune {
+	var data []rune
+	k := 0
+	for _, c := range []rune(f.Picture) {
+		if k >= len(raw) && (len(raw) == 0 || isPictureSlot(c)) {
+			break
+		}
+		if isPictureSlot(c) {
+			data = append(data, raw[k])
+			k++
+		} else {
+			data = append(data, c)
+		}
+	}
+	return data
+}
 
-	if p.Field[i].hDataPos < len(p.Field[i].RData) {
-		p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos+1], p.Field[i].RData[p.Field[i].hDataPos:]...)
-		p.Field[i].RData[p.Field[i].hDataPos] = r
+func (f *DataField) conformsPicture(data []rune) bool {
+	pic := []rune(f.Picture)
+	if len(data) > len(pic) {
+		return false
+	}
+	for i, r := range data {
+		if isPictureSlot(pic[i]) {
+			if _, ok := matchPicture(pic[i], r); !ok {
+				return false
+			}
+		} else if pic[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *DataField) setPictureRaw(raw []rune) bool {
+	slots := f.pictureSlots()
+	if len(raw) > len(slots) {
+		return false
+	}
+	pic := []rune(f.Picture)
+	data := make([]rune, len(raw))
+	for k := range raw {
+		r, ok := matchPicture(pic[slots[k]], raw[k])
+		if !ok {
+			return false
+		}
+		data[k] = r
+	}
+	f.RData = f.formatPicture(data)
+	return true
+}
+
+// pictureData accepts either the formatted or the raw value.
+func (f *DataField) pictureData(s string) []rune {
+	data := []rune(s)
+	if len(f.Picture) == 0 || f.conformsPicture(data) {
+		return data
+	}
+	save := f.RData
+	if f.setPictureRaw(data) {
+		data = f.RData
+	}
+	f.RData = save
+	return data
+}
+
+func (f *DataField) skipPictureLiteral() {
+	for f.hDataPos < len(f.RData) && f.isPictureLiteral(f.hDataPos) {
+		f.hDataPos++
+	}
+}
+
+func (f *DataField) inputPicture(r rune) bool {
+	if f.isPictureLiteral(f.hDataPos) && []rune(f.Picture)[f.hDataPos] == r {
+		if f.hDataPos < len(f.RData) {
+			f.hDataPos++
+			f.skipPictureLiteral()
+			return true
+		}
+		return false
+	}
+
+	pos := f.rawPicturePos(f.hDataPos)
+	raw := f.rawPicture(f.RData)
+	if taps.overwrite && pos < len(raw) {
+		raw[pos] = r
 	} else {
-		p.Field[i].RData = append(p.Field[i].RData, r)
+		raw = append(raw[:pos], append([]rune{r}, raw[pos:]...)...)
+	}
+	if !f.setPictureRaw(raw) {
+		return false
 	}
+	f.hDataPos = f.pictureSlots()[pos] + 1
+	f.skipPictureLiteral()
+	return true
+}
 
-	if p.Field[i].hCursorX < p.Field[i].GetFieldLen() {
-		p.Field[i].hCursorX += runewidth.RuneWidth(p.Field[i].RData[p.Field[i].hDataPos])
+func (f *DataField) deletePicture(pos int) bool {
+	raw := f.rawPicture(f.RData)
+	if pos < 0 || pos >= len(raw) {
+		return false
 	}
+	raw = append(raw[:pos], raw[pos+1:]...)
+	return f.setPictureRaw(raw)
+}
 
-	p.Field[i].hDataPos++
+// ---------------------------------------------
+func (p *Panel) input_data(i int, r rune) {
+	if len(p.Field[i].Picture) > 0 {
+		// Check picture string
+		if !p.Field[i].inputPicture(r) {
+			return
+		}
+	} else {
+		overwrite := taps.overwrite && p.Field[i].hDataPos < len(p.Field[i].RData)
+
+		// Check data length
+		if !overwrite && p.Field[i].DataLen > 0 && len(p.Field[i].RData) == p.Field[i].DataLen {
+			return
+		}
+
+		// Numeric check
+		if p.Field[i].Attr == "N" || p.Field[i].Attr == "n" {
+			if p.Field[i].isNumeric(r) == false {
+				return
+			}
+		}
+
+		if overwrite {
+			p.Field[i].overwriteData(r)
+		} else if p.Field[i].hDataPos < len(p.Field[i].RData) {
+			p.Field[i].RData = append(p.Field[i].RData[:p.Field[i].hDataPos+1], p.Field[i].RData[p.Field[i].hDataPos:]...)
+			p.Field[i].RData[p.Field[i].hDataPos] = r
+		} else {
+			p.Field[i].RData = append(p.Field[i].RData, r)
+		}
+
+		if p.Field[i].hCursorX < p.Field[i].GetFieldLen() {
+			p.Field[i].hCursorX += runewidth.RuneWidth(p.Field[i].RData[p.Field[i].hDataPos])
+		}
+
+		p.Field[i].hDataPos++
+	}
+	p.setModified(i)
 
 	p.Field[i].setStartDataPos()
 	p.Field[i].setCursorPos()
@@ -1238,6 +1621,18 @@ func (p *Panel) Get(n string) string {
 	return ""
 }
 
+// GetRaw returns the data without the literals of the picture.
+func (p *Panel) GetRaw(n string) string {
+	f := p.GetDataField(n)
+	if f != nil {
+		if len(f.Picture) == 0 {
+			return p.Get(n)
+		}
+		return string(f.rawPicture(f.RData))
+	}
+	return ""
+}
+
 func (p *Panel) GetGridData(n string, col, row int) string {
 	return p.Get(n + GRID_SEP + fmt.Sprintf("%03d:%03d", col, row))
 
@@ -1261,8 +1656,12 @@ func (p *Panel) GetGridList(n string, col, row int) []string {
 func (p *Panel) Store(sData string, n string) {
 	f := p.GetDataField(n)
 	if f != nil {
-		f.Data = sData
-		f.RData = []rune(sData)
+		f.RData = f.pictureData(sData)
+		f.Data = string(f.RData)
+		if !isListMode(f) {
+			f.stored = append([]rune(nil), f.RData...)
+			f.modified = false
+		}
 	}
 }
 
@@ -1273,8 +1672,13 @@ func (p *Panel) StoreGridData(sData string, n string, col, row int) {
 func (p *Panel) StoreList(listData []string, n string) {
 	f := p.getFirstList(n)
 	if f != nil {
+		if f.provider != nil {
+			p.SetListProvider(n, nil)
+		}
 		f.setListData(listData)
 		f.listStart = 0
+		f.storedList = append([]string(nil), listData...)
+		f.modified = false
 		return
 	}
 }
@@ -1283,6 +1687,74 @@ func (p *Panel) StoreGridList(listData []string, n string, col, row int) {
 	p.StoreList(listData, n + GRID_SEP + fmt.Sprintf("%03d:%03d", col, row))
 }
 
+// ---------------------------------------------
+// Modified
+// ---------------------------------------------
+// setModified marks the field as changed by the user.
+// For list fields, the first field of the list is marked.
+func (p *Panel) setModified(i int) {
+	if isListMode(p.Field[i]) {
+		if s := p.getFirstList(p.Field[i].Name); s != nil {
+			s.modified = true
+		}
+		return
+	}
+	p.Field[i].modified = true
+}
+
+func (p *Panel) IsModified() bool {
+	for _, f := range p.Field {
+		if f.modified {
+			return true
+		}
+	}
+	return false
+}
+
+// ModifiedFields returns the names of the fields changed since Store.
+// List fields are returned by the name of the list.
+func (p *Panel) ModifiedFields() []string {
+	var names []string
+	for _, f := range p.Field {
+		if !f.modified {
+			continue
+		}
+		if isListMode(f) {
+			names = append(names, strings.Split(f.Name, LIST_SEP)[0])
+		} else {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// MarkClean clears the modified flags. The data is not changed.
+func (p *Panel) MarkClean() {
+	for _, f := range p.Field {
+		f.modified = false
+	}
+}
+
+// Revert restores the data last stored by Store or StoreList.
+func (p *Panel) Revert(n string) {
+	if s := p.getFirstList(n); s != nil {
+		s.setListData(s.storedList)
+		s.listStart = 0
+		s.modified = false
+		return
+	}
+	f := p.GetDataField(n)
+	if f != nil {
+		f.RData = append([]rune(nil), f.stored...)
+		f.Data = string(f.RData)
+		f.hDataPos = 0
+		f.hStartDataPos = 0
+		f.hCursorX = 0
+		f.hCursorY = 0
+		f.modified = false
+	}
+}
+
 // ============================================
 // List
 // ============================================-
@@ -1310,6 +1782,9 @@ func (p *Panel) GetListCount(n string) (string, int) {
 
 // -------------------------------
 func (f *DataField) getListData() []string {
+	if f.provider != nil {
+		return f.getProviderData()
+	}
 	var listData []string
 	for i := 0; i < len(f.listData); i++ {
 		listData = append(listData, f.listData[i].data)
@@ -1411,6 +1886,9 @@ func (p *Panel) getListCountUntil(n string) (string, int) {
 }
 
 func getListDataLen(f *DataField) int {
+	if f.provider != nil {
+		return f.provider.Len()
+	}
 	return len(f.listData)
 }
 
@@ -1447,6 +1925,7 @@ func (p *Panel) expandList(i int) {
 		t.data = string(p.Field[i].RData[p.Field[i].hDataPos:])
 		s.listData = append(s.listData, t)
 	}
+	s.modified = true
 }
 
 func (p *Panel) concateList(i int) {
@@ -1479,7 +1958,7 @@ func (p *Panel) concateList2(i int, isConcate bool) {
 	if len(s.listData) > start+curNum {
 		s.listData = append(s.listData[:start+curNum], s.listData[start+curNum+1:]...)
 	}
-
+	s.modified = true
 }
 
 func (p *Panel) killList(i int) {
@@ -1489,11 +1968,13 @@ func (p *Panel) killList(i int) {
 	}
 	start := s.listStart
 	_, curNum := p.getListCountUntil(p.Field[i].Name)
+	pushKill(string(p.Field[i].RData[p.Field[i].hDataPos:]))
 	s.listData[start+curNum].data = string(p.Field[i].RData[:p.Field[i].hDataPos])
 	s.listData[start+curNum].hDataPos = p.Field[i].hDataPos
 	s.listData[start+curNum].hStartDataPos = p.Field[i].hStartDataPos
 	s.listData[start+curNum].hCursorX = p.Field[i].hCursorX
 	s.listData[start+curNum].hCursorY = p.Field[i].hCursorY
+	s.modified = true
 }
 
 func (p *Panel) insertList(i int) {
@@ -1517,11 +1998,12 @@ func (p *Panel) insertList(i int) {
 		t.data = ""
 		s.listData = append(s.listData, t)
 	}
+	s.modified = true
 }
 
 func (p *Panel) updateList(n string) {
 	s := p.getFirstList(n)
-	if s == nil {
+	if s == nil || s.provider != nil {
 		return
 	}
 
@@ -1560,11 +2042,7 @@ func getClickedField(sf []*DataField, e *tcell.EventMouse) (*DataField, int) {
 	editFlag := false
 
 	for i := len(sf) - 1; i >= 0; i-- {
-		w := GetFieldX(sf[i].FieldLen)
-		if sf[i].FieldLen == 0 {
-			w = len(sf[i].RData)
-		}
-		if x >= GetFieldX(sf[i].X) && x < GetFieldX(sf[i].X)+w && y == GetFieldY(sf[i].Y) && !editFlag {
+		if sf[i].contains(x, y) && !editFlag {
 			if isSelect(sf[i]) && len(sf[i].RData) > 0 {
 				return sf[i], i
 			}
@@ -1579,6 +2057,15 @@ func getClickedField(sf []*DataField, e *tcell.EventMouse) (*DataField, int) {
 	}
 	return nil, -1
 }
+
+func (f *DataField) contains(x, y int) bool {
+	w := GetFieldX(f.FieldLen)
+	if f.FieldLen == 0 {
+		w = len(f.RData)
+	}
+	return x >= GetFieldX(f.X) && x < GetFieldX(f.X)+w && y == GetFieldY(f.Y)
+}
+
 func checkExitKey(sf []*DataField, r rune) (*DataField, int) {
 	//@@@@
 	s := string(r)
@@ -1603,11 +2090,19 @@ func checkExitKey(sf []*DataField, r rune) (*DataField, int) {
 }
 
 func SetNormalStyle(f *DataField) {
+	f.hMode = f.hMode & (0xff ^ FOCUS_MODE)
 	f.currentStyle = f.normalStyle
+	if isError(f) {
+		f.currentStyle = f.errorStyle
+	}
 }
 
 func SetFocusedStyle(f *DataField) {
+	f.hMode = f.hMode | FOCUS_MODE
 	f.currentStyle = f.focusedStyle
+	if isError(f) {
+		f.currentStyle = f.errorStyle
+	}
 }
 
 // ---------------------------------------------
@@ -1818,7 +2313,7 @@ func (p *Panel) doEdit(i int, cKey tcell.Key, rKey rune) (bool, int) {
 		p.input_bs(i)
 	}
 
-	if cKey == tcell.KeyCtrlA {
+	if cKey == tcell.KeyCtrlA || cKey == tcell.KeyHome {
 		p.Field[i].goFirstLinePos()
 		p.Field[i].Say()
 	}
@@ -1829,19 +2324,28 @@ func (p *Panel) doEdit(i int, cKey tcell.Key, rKey rune) (bool, int) {
 
 	// ---------------------------------------
 	if cKey == tcell.KeyEnter {
-		i = p.nextSelect(i, cKey)
+		if p.hasTabIndex() {
+			i = p.tabSelect(i, cKey)
+		} else {
+			i = p.nextSelect(i, cKey)
+		}
 		SetFocusedStyle(p.Field[i])
 		p.Field[i].Say()
 		return true, i
 	}
 
 	if cKey == tcell.KeyCtrlK {
-		p.Store(string(p.Field[i].RData[:p.Field[i].hDataPos]), p.Field[i].Name)
+		if p.Field[i].hDataPos < len(p.Field[i].RData) {
+			pushKill(string(p.Field[i].RData[p.Field[i].hDataPos:]))
+			p.Field[i].RData = p.Field[i].pictureData(string(p.Field[i].RData[:p.Field[i].hDataPos]))
+			p.Field[i].Data = string(p.Field[i].RData)
+			p.setModified(i)
+		}
 		p.Field[i].Say()
 		return true, i
 	}
 
-	if cKey == tcell.KeyCtrlE {
+	if cKey == tcell.KeyCtrlE || cKey == tcell.KeyEnd {
 		p.Field[i].hDataPos = len(p.Field[i].RData)
 		p.Field[i].resetStartDataPos()
 		p.Field[i].resetDataPos(p.Field[i].GetFieldLen(), p.Field[i].hCursorY)
@@ -2072,7 +2576,7 @@ func (p *Panel) doList(i int, cKey tcell.Key, rKey rune) (bool, int) {
 		return true, i
 	}
 
-	if cKey == tcell.KeyCtrlE {
+	if cKey == tcell.KeyCtrlE || cKey == tcell.KeyEnd {
 		count := lines - p.Field[i].hCursorY
 		for {
 			if count == 0 {
@@ -2098,26 +2602,24 @@ func (p *Panel) doList(i int, cKey tcell.Key, rKey rune) (bool, int) {
 }
 
 // ---------------------------------------------
-func isExitKey(exitKey []string, cKey tcell.Key) bool {
+func isExitKey(exitKey []string, ev *tcell.EventKey) bool {
 	for _, x := range exitKey {
-		for k, v := range tcell.KeyNames {
-			if v == x && k == cKey {
-				//log.Printf("isExitKet v:%s x:%s\n", v, x)
-
-				return true
-			}
+		k, err := parseKeySpec(x)
+		if err == nil && k.match(ev) {
+			return true
 		}
 	}
 	return false
 }
 
 // ---------------------------------------------
-func (p *Panel) checkBreak(i int, cKey tcell.Key, rKey rune) (bool, string) {
-	if isExitKey(p.ExitKey, cKey) || isExitKey(p.Field[i].ExitKey, cKey) {
+func (p *Panel) checkBreak(i int, ev *tcell.EventKey) (bool, string) {
+	cKey, rKey := ev.Key(), ev.Rune()
+	if isExitKey(p.ExitKey, ev) || isExitKey(p.Field[i].ExitKey, ev) {
 		SetNormalStyle(p.Field[i])
 		p.Field[i].Say()
 		p.SelectFocus = i
-		if isExitKey(p.ExitKey, cKey) {
+		if isExitKey(p.ExitKey, ev) {
 			return true, ""
 		} else {
 			return true, p.Field[i].Name
@@ -2193,11 +2695,15 @@ func (p *Panel) locateField(i int) int {
 func (p *Panel) read2(i int) (tcell.Key, string) {
 	var isContinue bool
 
+	p.lastKey = nil
+	p.lastMouse = nil
+	p.sayMode()
 	i = p.locateField(i)
 	if i == INVALID_KEY {
 		//return INVALID_KEY, ""
 		return tcell.KeyEscape, ""
 	}
+	last := i
 
 	for {
 		if isDisabled(p.Field[i]) && !(isListMode(p.Field[i])) {
@@ -2210,45 +2716,143 @@ func (p *Panel) read2(i int) (tcell.Key, string) {
 			continue
 		}
 
+		if i != last {
+			p.Field[last].clearSelection()
+			p.leaveField(last)
+			p.Field[i].Say()
+			last = i
+		}
+
 		ev := taps.screen.PollEvent()
 		switch ev := ev.(type) {
 		case *tcell.EventKey:
-			cKey := ev.Key()
-			rKey := ev.Rune()
-			isBreak, n := p.checkBreak(i, cKey, rKey)
+			cKey, rKey, mod, action := p.translateKey(i, ev)
+			if action == ACTION_NONE {
+				continue
+			}
+			lastYank := p.lastYank
+			p.lastYank = nil
+			p.lastKey = ev
+			p.lastMouse = nil
+			kev := tcell.NewEventKey(cKey, rKey, mod)
+			isBreak, n := p.checkBreak(i, kev)
 			if isBreak {
+				if j := p.checkSubmit(kev, n); j != INVALID_KEY {
+					i, last = j, j
+					continue
+				}
 				return cKey, n
 			}
 
+			if p.isHelpKey(kev) {
+				p.showHelp(i)
+				continue
+			}
+
+			if p.isUndoKey(kev) || action == ACTION_UNDO {
+				i = p.undo(i)
+				continue
+			}
+			if p.isRedoKey(kev) || action == ACTION_REDO {
+				i = p.redo(i)
+				continue
+			}
+
+			if isEdit(p.Field[i]) && !isDisabled(p.Field[i]) && !isBrowseMode(p.Field[i]) {
+				if p.doSelection(i, cKey, rKey, mod) {
+					continue
+				}
+				if p.isYankKey(kev) || action == ACTION_YANK {
+					p.requestYank(i)
+					continue
+				}
+				if p.isYankPopKey(kev) || action == ACTION_YANK_POP {
+					p.yankPop(i, lastYank)
+					continue
+				}
+				if p.doWord(i, cKey, rKey, mod) {
+					continue
+				}
+				if cKey == tcell.KeyInsert {
+					p.toggleOverwrite()
+					continue
+				}
+			}
+
+			if p.isGridEdge(i, cKey) {
+				i = p.arrowSelect(i, cKey)
+				SetFocusedStyle(p.Field[i])
+				p.Field[i].Say()
+				continue
+			}
+
+			if cKey == tcell.KeyHome || cKey == tcell.KeyEnd || cKey == tcell.KeyPgUp || cKey == tcell.KeyPgDn {
+				isContinue, i = p.doPage(i, cKey, mod)
+				if isContinue {
+					continue
+				}
+			}
+
 			if isEdit(p.Field[i]) && !isDisabled(p.Field[i]) {
+				u := p.getUndoState(i)
 				isContinue, i = p.doEdit(i, cKey, rKey)
+				p.pushUndo(u, cKey)
 				if isContinue {
 					continue
 				}
 			}
 
 			if isListMode(p.Field[i]) {
+				u := p.getUndoState(i)
 				isContinue, i = p.doList(i, cKey, rKey)
+				p.pushUndo(u, cKey)
 				if isContinue {
 					continue
 				}
 			}
 
-			if (isSelect(p.Field[i]) && cKey == tcell.KeyLeft) || cKey == tcell.KeyUp || cKey == tcell.KeyBacktab {
-				i = p.priorSelect(i, cKey)
+			if cKey == tcell.KeyUp || cKey == tcell.KeyDown || (isSelect(p.Field[i]) && (cKey == tcell.KeyLeft || cKey == tcell.KeyRight)) {
+				i = p.arrowSelect(i, cKey)
 				SetFocusedStyle(p.Field[i])
 				p.Field[i].resetDataPos(p.Field[i].hCursorX, p.Field[i].hCursorY)
 				p.Field[i].Say()
 			}
 
-			if (isSelect(p.Field[i]) && cKey == tcell.KeyRight) || cKey == tcell.KeyDown || cKey == tcell.KeyTab {
-				i = p.nextSelect(i, cKey)
+			if cKey == tcell.KeyTab || cKey == tcell.KeyBacktab {
+				if p.hasTabIndex() {
+					i = p.tabSelect(i, cKey)
+				} else if cKey == tcell.KeyTab {
+					i = p.nextSelect(i, cKey)
+				} else {
+					i = p.priorSelect(i, cKey)
+				}
 				SetFocusedStyle(p.Field[i])
 				p.Field[i].resetDataPos(p.Field[i].hCursorX, p.Field[i].hCursorY)
 				p.Field[i].Say()
 			}
 
+		case *tcell.EventInterrupt:
+			if _, ok := ev.Data().(toastEvent); ok {
+				drawToasts()
+			}
+			if e, ok := ev.Data().(clipboardTimeout); ok && e.y == p.pendingYank {
+				p.doClipboard(nil)
+			}
+			if e, ok := ev.Data().(listEvent); ok && e.p == p {
+				p.sayListData(e.name, false)
+				SetFocusedStyle(p.Field[i])
+				p.Field[i].Say()
+			}
+
+		case *tcell.EventClipboard:
+			p.doClipboard(ev.Data())
+
 		case *tcell.EventMouse:
+			p.lastKey = nil
+			p.lastMouse = ev
+			if ev.Buttons() == tcell.ButtonNone {
+				p.dragging = false
+			}
 			/*
 				if ev.Buttons()&tcell.Button5 != 0 {
 
@@ -2258,16 +2862,30 @@ func (p *Panel) read2(i int) (tcell.Key, string) {
 				}
 			*/
 			if ev.Buttons()&tcell.Button1 != 0 {
+				if key := p.getClickedKey(ev); key != nil {
+					taps.screen.PostEvent(key)
+					continue
+				}
+				if p.dragTo(i, ev) {
+					continue
+				}
 				f, num := getClickedField(p.Field, ev)
 				if f != nil {
 					SetNormalStyle(p.Field[i])
 					p.Field[i].Say()
 					p.SelectFocus = num
 					if isSelect(f) {
+						if j := p.checkSubmit(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), f.Name); j != INVALID_KEY {
+							i, last = j, j
+							continue
+						}
 						return tcell.KeyEnter, f.Name
 					}
 					if isEdit(f) {
 						i = num
+						if !isBrowseMode(f) {
+							p.startDrag(i)
+						}
 						SetFocusedStyle(p.Field[i])
 
 						p.Field[i].Say()
@@ -2280,6 +2898,18 @@ func (p *Panel) read2(i int) (tcell.Key, string) {
 				if f != nil {
 					if isListMode(f) {
 						s := p.getFirstList(f.Name)
+						if s != nil && ev.Modifiers()&tcell.ModCtrl != 0 {
+							// Ctrl + wheel scrolls by a page
+							_, cnt := p.getLastList(s.Name)
+							if ev.Buttons()&tcell.WheelUp != 0 {
+								cnt = -cnt
+							}
+							if p.scrollList(s, cnt) {
+								SetFocusedStyle(p.Field[i])
+								p.Field[i].Say()
+							}
+							continue
+						}
 						if s != nil {
 							if ev.Buttons()&tcell.WheelUp != 0 {
 								if s.listStart > 0 {
@@ -2305,8 +2935,8 @@ func (p *Panel) read2(i int) (tcell.Key, string) {
 }
 
 func (p *Panel) Read() (tcell.Key, string) {
-	cKey, n := p.read2(p.SelectFocus)
-	return cKey, n
+	e := p.ReadEvent()
+	return e.Key, e.Name
 }
 
 // ---------------------------------------
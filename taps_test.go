@@ -0,0 +1,28 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+var testStyleMatrix = [][]string{
+	{"label", "white", "black"},
+	{"edit", "white", "black"},
+	{"edit_focus", "yellow", "black"},
+}
+
+// newTestScreen makes the panels draw on a simulation screen.
+func newTestScreen(t *testing.T) tcell.SimulationScreen {
+	t.Helper()
+	s := tcell.NewSimulationScreen("")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetSize(80, 25)
+	taps.screen = s
+	t.Cleanup(func() {
+		s.Fini()
+		taps.screen = nil
+	})
+	return s
+}
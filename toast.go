@@ -0,0 +1,175 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"strings"
+	"time"
+)
+
+const (
+	NOTIFY_INFO = iota
+	NOTIFY_WARNING
+	NOTIFY_ERROR
+)
+
+const (
+	TOAST_TOP_RIGHT = iota
+	TOAST_TOP_LEFT
+	TOAST_BOTTOM_RIGHT
+	TOAST_BOTTOM_LEFT
+)
+
+const (
+	TOAST_TIMEOUT = 3 * time.Second
+	TOAST_FADE    = 500 * time.Millisecond
+)
+
+type toast struct {
+	msg    string
+	level  int
+	expire time.Time
+}
+
+// toastEvent wakes up Read so that the toasts are redrawn.
+type toastEvent struct{}
+
+// ---------------------------------------------
+// Notify
+// ---------------------------------------------
+// Notify posts a toast message. It may be called from any goroutine;
+// the toast is drawn by the goroutine running Read.
+func Notify(msg string, level int) {
+	taps.toastMu.Lock()
+	timeout := taps.toastTimeout
+	if timeout <= 0 {
+		timeout = TOAST_TIMEOUT
+	}
+	t := &toast{
+		msg:    strings.ReplaceAll(msg, "\n", " "),
+		level:  level,
+		expire: time.Now().Add(timeout),
+	}
+	taps.toasts = append(taps.toasts, t)
+	taps.toastMu.Unlock()
+
+	postToastEvent()
+	if timeout > TOAST_FADE {
+		time.AfterFunc(timeout-TOAST_FADE, postToastEvent)
+	}
+	time.AfterFunc(timeout, postToastEvent)
+}
+
+func SetNotifyTimeout(timeout time.Duration) {
+	taps.toastMu.Lock()
+	defer taps.toastMu.Unlock()
+	taps.toastTimeout = timeout
+}
+
+func SetNotifyPosition(corner int) {
+	taps.toastMu.Lock()
+	defer taps.toastMu.Unlock()
+	taps.toastCorner = corner
+}
+
+func SetNotifyStyle(level int, style tcell.Style) {
+	taps.toastMu.Lock()
+	defer taps.toastMu.Unlock()
+	if taps.toastStyle == nil {
+		taps.toastStyle = make(map[int]tcell.Style)
+	}
+	taps.toastStyle[level] = style
+}
+
+func postToastEvent() {
+	if taps.screen != nil {
+		taps.screen.PostEvent(tcell.NewEventInterrupt(toastEvent{}))
+	}
+}
+
+func getToastStyle(level int) tcell.Style {
+	if style, ok := taps.toastStyle[level]; ok {
+		return style
+	}
+	switch level {
+	case NOTIFY_WARNING:
+		return tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow)
+	case NOTIFY_ERROR:
+		return tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed).Bold(true)
+	}
+	return tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue)
+}
+
+// ---------------------------------------------
+// Draw toasts
+// ---------------------------------------------
+// drawToasts draws the live toasts over the screen and puts back the
+// cells of the toasts which have disappeared.
+func drawToasts() {
+	if taps.screen == nil {
+		return
+	}
+
+	now := time.Now()
+	taps.toastMu.Lock()
+	live := taps.toasts[:0]
+	for _, t := range taps.toasts {
+		if now.Before(t.expire) {
+			live = append(live, t)
+		}
+	}
+	taps.toasts = live
+	list := make([]*toast, len(live))
+	copy(list, live)
+	styles := make([]tcell.Style, len(list))
+	for n, t := range list {
+		styles[n] = getToastStyle(t.level)
+		if t.expire.Sub(now) <= TOAST_FADE {
+			styles[n] = styles[n].Dim(true)
+		}
+	}
+	corner := taps.toastCorner
+	taps.toastMu.Unlock()
+
+	old := taps.covered
+	taps.covered = make(map[cellPos]bool)
+	mx, my := GetWindowSize()
+	for n, t := range list {
+		s := []rune(" " + t.msg + " ")
+		w := 0
+		for k := 0; k < len(s); k++ {
+			if w+runewidth.RuneWidth(s[k]) > mx-1 {
+				s = s[:k]
+				break
+			}
+			w += runewidth.RuneWidth(s[k])
+		}
+
+		x := 1
+		if corner == TOAST_TOP_RIGHT || corner == TOAST_BOTTOM_RIGHT {
+			x = mx - w
+		}
+		y := 1 + n
+		if corner == TOAST_BOTTOM_RIGHT || corner == TOAST_BOTTOM_LEFT {
+			y = my - 1 - n
+		}
+		if y < 0 || y >= my {
+			break
+		}
+
+		for k := 0; k < len(s); k++ {
+			taps.screen.SetContent(x, y, s[k], nil, styles[n])
+			for d := 0; d < runewidth.RuneWidth(s[k]); d++ {
+				taps.covered[cellPos{x + d, y}] = true
+			}
+			x += runewidth.RuneWidth(s[k])
+		}
+	}
+
+	for pos := range old {
+		if !taps.covered[pos] {
+			restoreCell(pos.x, pos.y)
+		}
+	}
+	taps.screen.Show()
+}
@@ -0,0 +1,154 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+const UNDO_MAX = 100
+
+// undoState is the data and the cursor of the field before an edit.
+type undoState struct {
+	focus         int
+	rData         []rune
+	hDataPos      int
+	hStartDataPos int
+	hCursorX      int
+	hCursorY      int
+	listStart     int
+	listData      []ListField
+	modified      bool
+	typed         bool
+}
+
+// ---------------------------------------------
+// Undo state
+// ---------------------------------------------
+func (p *Panel) getUndoState(i int) *undoState {
+	f := p.Field[i]
+	u := &undoState{
+		focus:         i,
+		rData:         append([]rune(nil), f.RData...),
+		hDataPos:      f.hDataPos,
+		hStartDataPos: f.hStartDataPos,
+		hCursorX:      f.hCursorX,
+		hCursorY:      f.hCursorY,
+		modified:      f.modified,
+	}
+	if isListMode(f) {
+		if s := p.getFirstList(f.Name); s != nil {
+			u.listStart = s.listStart
+			u.listData = append([]ListField(nil), s.listData...)
+			u.modified = s.modified
+		}
+	}
+	return u
+}
+
+func (u *undoState) isChanged(v *undoState) bool {
+	if string(u.rData) != string(v.rData) || len(u.listData) != len(v.listData) {
+		return true
+	}
+	for k := range u.listData {
+		if u.listData[k].data != v.listData[k].data {
+			return true
+		}
+	}
+	return false
+}
+
+// pushUndo saves u if the field has been changed since u was taken.
+// Runes typed in a row are undone at once.
+func (p *Panel) pushUndo(u *undoState, cKey tcell.Key) {
+	if !u.isChanged(p.getUndoState(u.focus)) {
+		return
+	}
+	p.redoList = nil
+	u.typed = cKey == tcell.KeyRune
+	if n := len(p.undoList); n > 0 && u.typed && p.undoList[n-1].typed && p.undoList[n-1].focus == u.focus {
+		return
+	}
+	p.undoList = append(p.undoList, u)
+	if len(p.undoList) > UNDO_MAX {
+		p.undoList = p.undoList[1:]
+	}
+}
+
+func (p *Panel) restoreUndoState(i int, u *undoState) int {
+	f := p.Field[u.focus]
+	if isListMode(f) {
+		if s := p.getFirstList(f.Name); s != nil {
+			s.listStart = u.listStart
+			s.listData = append([]ListField(nil), u.listData...)
+			s.modified = u.modified
+			p.SayListData(s.Name)
+		}
+	} else {
+		f.modified = u.modified
+	}
+	f.RData = append([]rune(nil), u.rData...)
+	f.Data = string(f.RData)
+	f.hDataPos = u.hDataPos
+	f.hStartDataPos = u.hStartDataPos
+	f.hCursorX = u.hCursorX
+	f.hCursorY = u.hCursorY
+
+	if i != u.focus {
+		SetNormalStyle(p.Field[i])
+		p.Field[i].Say()
+	}
+	SetFocusedStyle(f)
+	f.Say()
+	return u.focus
+}
+
+// ---------------------------------------------
+// Undo / Redo
+// ---------------------------------------------
+func (p *Panel) isUndoKey(ev *tcell.EventKey) bool {
+	if len(p.UndoKey) == 0 {
+		return ev.Key() == tcell.KeyCtrlZ
+	}
+	return isExitKey(p.UndoKey, ev)
+}
+
+// isRedoKey reports whether ev is RedoKey. The default is Ctrl-Y, or
+// Ctrl-R if YankKey has Ctrl-Y.
+func (p *Panel) isRedoKey(ev *tcell.EventKey) bool {
+	if len(p.RedoKey) == 0 {
+		if isExitKey(p.YankKey, tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModNone)) {
+			return ev.Key() == tcell.KeyCtrlR
+		}
+		return ev.Key() == tcell.KeyCtrlY
+	}
+	return isExitKey(p.RedoKey, ev)
+}
+
+// undo restores the last edit, and returns the number of the field to focus.
+func (p *Panel) undo(i int) int {
+	n := len(p.undoList)
+	if n == 0 {
+		return i
+	}
+	u := p.undoList[n-1]
+	p.undoList = p.undoList[:n-1]
+	p.redoList = append(p.redoList, p.getUndoState(u.focus))
+	return p.restoreUndoState(i, u)
+}
+
+func (p *Panel) redo(i int) int {
+	n := len(p.redoList)
+	if n == 0 {
+		return i
+	}
+	u := p.redoList[n-1]
+	p.redoList = p.redoList[:n-1]
+	p.undoList = append(p.undoList, p.getUndoState(u.focus))
+	return p.restoreUndoState(i, u)
+}
+
+// ClearUndo discards the undo and redo history, e.g. after a new record
+// is stored to the panel.
+func (p *Panel) ClearUndo() {
+	p.undoList = nil
+	p.redoList = nil
+}
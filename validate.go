@@ -0,0 +1,344 @@
+package taps
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is returned by the panel validator.
+type FieldError struct {
+	Name    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// FieldRule is the validation rule of [[Field]].
+type FieldRule struct {
+	Required     bool
+	MinLen       int
+	MaxValue     *float64
+	MinValue     *float64
+	Regex        string
+	ErrorMessage string
+}
+
+// ---------------------------------------------
+// Field rule
+// ---------------------------------------------
+func (p *Panel) setFieldRule() {
+	errorStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed)
+	if p.ErrorStyle != "" {
+		errorStyle, _ = getStyle(p.ErrorStyle, p.styleMatrix)
+	}
+
+	regex := make(map[string]*regexp.Regexp)
+	for _, f := range p.Field {
+		f.errorStyle = errorStyle
+		if f.Regex == "" {
+			continue
+		}
+		if regex[f.Regex] == nil {
+			re, err := regexp.Compile(f.Regex)
+			if err != nil {
+				// The field is always invalid, and shows the error.
+				f.regexErr = fmt.Errorf("taps: %s: invalid Regex: %v", getBaseName(f.Name), err)
+				continue
+			}
+			regex[f.Regex] = re
+		}
+		f.regex = regex[f.Regex]
+	}
+}
+
+func isError(f *DataField) bool {
+	if f.hMode&ERROR_MODE != 0x00 {
+		return true
+	}
+	return false
+}
+
+func getBaseName(name string) string {
+	return strings.Split(strings.Split(name, GRID_SEP)[0], LIST_SEP)[0]
+}
+
+// validate returns the error message, or "" if the data is valid.
+func (f *DataField) validate() string {
+	v := string(f.RData)
+	msg := ""
+	name := getBaseName(f.Name)
+
+	if strings.TrimSpace(v) == "" {
+		if f.Required {
+			msg = fmt.Sprintf("ERROR: %s is required .", name)
+		}
+	} else if f.MinLen > 0 && utf8.RuneCountInString(v) < f.MinLen {
+		msg = fmt.Sprintf("ERROR: %s needs at least %d characters .", name, f.MinLen)
+	} else if f.MinValue != nil || f.MaxValue != nil {
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			msg = fmt.Sprintf("ERROR: %s must be numeric .", name)
+		} else if f.MinValue != nil && n < *f.MinValue {
+			msg = fmt.Sprintf("ERROR: %s must be %v or more .", name, *f.MinValue)
+		} else if f.MaxValue != nil && n > *f.MaxValue {
+			msg = fmt.Sprintf("ERROR: %s must be %v or less .", name, *f.MaxValue)
+		}
+	}
+
+	if msg == "" && v != "" && f.regex != nil && !f.regex.MatchString(v) {
+		msg = fmt.Sprintf("ERROR: %s format error .", name)
+	}
+
+	if msg != "" && f.ErrorMessage != "" {
+		msg = f.ErrorMessage
+	}
+	if msg == "" && f.regexErr != nil {
+		msg = f.regexErr.Error()
+	}
+
+	if msg == "" && f.validator != nil {
+		if err := f.validator(v); err != nil {
+			msg = err.Error()
+		}
+	}
+	return msg
+}
+
+// ---------------------------------------------
+// Validator and Formatter
+// ---------------------------------------------
+// matchFields returns the field n, and the list rows or grid cells
+// made from n.
+func (p *Panel) matchFields(n string) []*DataField {
+	var fields []*DataField
+	for _, f := range p.Field {
+		if f.Name == n || strings.HasPrefix(f.Name, n+LIST_SEP) || strings.HasPrefix(f.Name, n+GRID_SEP) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SetValidator registers the function which checks the data when the
+// focus leaves the field. The error is shown as the message.
+func (p *Panel) SetValidator(n string, validator func(value string) error) {
+	for _, f := range p.matchFields(n) {
+		f.validator = validator
+	}
+}
+
+// SetFormatter registers the function which formats the data for display.
+// The data itself is not changed.
+func (p *Panel) SetFormatter(n string, formatter func(raw string) string) {
+	for _, f := range p.matchFields(n) {
+		f.formatter = formatter
+	}
+}
+
+// ---------------------------------------------
+// Validate Panel
+// ---------------------------------------------
+// validateField checks the field and marks it with the error style.
+func (p *Panel) validateField(i int) string {
+	f := p.Field[i]
+	if !isEdit(f) || isDisabled(f) {
+		return ""
+	}
+
+	msg := f.validate()
+	if msg != "" {
+		f.hMode = f.hMode | ERROR_MODE
+	} else {
+		f.hMode = f.hMode & (0xff ^ ERROR_MODE)
+	}
+	return msg
+}
+
+func (p *Panel) showError(msg string) {
+	if msg == "" && p.errorMsg == "" {
+		return
+	}
+	p.errorMsg = msg
+	f := p.GetDataField(p.ErrorField)
+	if f == nil {
+		if msg != "" {
+			Notify(msg, NOTIFY_ERROR)
+		}
+		return
+	}
+	p.sayMessage(f, msg)
+}
+
+// sayMessage writes msg to the label field and erases the rest of the
+// previous message.
+func (p *Panel) sayMessage(f *DataField, msg string) {
+	w := runewidth.StringWidth(string(f.RData))
+	p.Store(msg, f.Name)
+	f.Say()
+	if f.FieldLen == 0 {
+		for x := runewidth.StringWidth(msg); x < w; x++ {
+			SetContent(GetFieldX(f.X)+x, GetFieldY(f.Y), ' ', nil, f.normalStyle)
+		}
+		Show()
+	}
+}
+
+func (p *Panel) hasError() bool {
+	for _, f := range p.Field {
+		if isError(f) && !isDisabled(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// leaveField is called when the focus leaves the field.
+func (p *Panel) leaveField(i int) {
+	if !isEdit(p.Field[i]) {
+		return
+	}
+	msg := p.validateField(i)
+	SetNormalStyle(p.Field[i])
+	p.Field[i].Say()
+	if msg != "" {
+		p.showError(msg)
+	} else if !p.hasError() {
+		p.showError("")
+	}
+}
+
+// Validate checks all fields, and returns the number of the first
+// invalid field or INVALID_KEY.
+func (p *Panel) Validate() int {
+	first := INVALID_KEY
+	msg := ""
+	for i := range p.Field {
+		m := p.validateField(i)
+		if m != "" && first == INVALID_KEY {
+			first = i
+			msg = m
+		}
+	}
+	p.showError(msg)
+	return first
+}
+
+func (p *Panel) isCancel(ev *tcell.EventKey, n string) bool {
+	if ev.Key() == tcell.KeyEscape || isExitKey(p.CancelKey, ev) {
+		return true
+	}
+	for _, x := range p.CancelKey {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSubmit validates the panel before Read returns, and returns the
+// number of the field to focus or INVALID_KEY.
+func (p *Panel) checkSubmit(ev *tcell.EventKey, n string) int {
+	if p.isCancel(ev, n) {
+		return INVALID_KEY
+	}
+	i := p.Validate()
+	if i == INVALID_KEY {
+		i = p.validatePanel()
+	}
+	for _, f := range p.Field {
+		if !isDisabled(f) && isEdit(f) {
+			SetNormalStyle(f)
+			f.Say()
+		}
+	}
+	if i == INVALID_KEY {
+		return INVALID_KEY
+	}
+
+	p.SelectFocus = i
+	SetFocusedStyle(p.Field[i])
+	p.Field[i].Say()
+	return i
+}
+
+// ---------------------------------------------
+// Panel validator
+// ---------------------------------------------
+// SetPanelValidator registers the function which checks the whole panel
+// before Read returns. It is called after all fields are valid.
+func (p *Panel) SetPanelValidator(validator func(*Panel) []FieldError) {
+	p.panelValidator = validator
+}
+
+// validatePanel calls the panel validator, shows the error summary and
+// returns the number of the first offending field or INVALID_KEY.
+// The fields marked by the previous call are cleared first, because
+// validateField does not check the fields other than edit fields.
+func (p *Panel) validatePanel() int {
+	for _, f := range p.Field {
+		if isError(f) {
+			f.hMode = f.hMode & (0xff ^ ERROR_MODE)
+			f.Say()
+		}
+	}
+	if p.panelValidator == nil {
+		return INVALID_KEY
+	}
+
+	errs := p.panelValidator(p)
+	var msgs []string
+	first := INVALID_KEY
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+		f, i := p.GetDataFieldWithNumber(e.Name)
+		if f == nil {
+			if fields := p.matchFields(e.Name); len(fields) > 0 {
+				f, i = p.GetDataFieldWithNumber(fields[0].Name)
+			}
+		}
+		if f == nil {
+			continue
+		}
+		f.hMode = f.hMode | ERROR_MODE
+		f.Say()
+		if first == INVALID_KEY {
+			first = i
+		}
+	}
+	p.showSummary(msgs)
+
+	if len(errs) > 0 && first == INVALID_KEY {
+		first = p.SelectFocus
+	}
+	return first
+}
+
+func (p *Panel) showSummary(msgs []string) {
+	if len(msgs) == 0 && len(p.summary) == 0 {
+		return
+	}
+	p.summary = msgs
+
+	if p.getFirstList(p.SummaryField) != nil {
+		p.StoreList(msgs, p.SummaryField)
+		p.SayListData(p.SummaryField)
+		return
+	}
+	if p.GetDataField(p.SummaryField) == nil {
+		msg := ""
+		if len(msgs) > 0 {
+			msg = msgs[0]
+		}
+		p.showError(msg)
+		return
+	}
+
+	p.sayMessage(p.GetDataField(p.SummaryField), strings.Join(msgs, " / "))
+}
@@ -0,0 +1,50 @@
+package taps
+
+import (
+	"testing"
+)
+
+const validateDoc = `
+[[Field]]
+Name = "E01"
+X = 1
+Y = 1
+FieldLen = 10
+Style = "edit, edit_focus"
+FieldType = "edit"
+
+[[Field]]
+Name = "S01"
+Data = "<OK>"
+X = 1
+Y = 3
+Style = "edit, edit_focus"
+FieldType = "select"
+`
+
+func TestPanelValidatorClearsError(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(validateDoc, testStyleMatrix, "")
+	bad := true
+	p.SetPanelValidator(func(p *Panel) []FieldError {
+		if bad {
+			return []FieldError{{Name: "S01", Message: "ERROR: S01"}}
+		}
+		return nil
+	})
+
+	if i := p.validatePanel(); i != p.GetFieldNumber("S01") {
+		t.Fatalf("validatePanel = %d, want S01", i)
+	}
+	if !p.hasError() {
+		t.Fatal("S01 is not marked")
+	}
+
+	bad = false
+	if i := p.validatePanel(); i != INVALID_KEY {
+		t.Fatalf("validatePanel = %d, want INVALID_KEY", i)
+	}
+	if p.hasError() || isError(p.GetDataField("S01")) {
+		t.Fatal("S01 is still marked")
+	}
+}
@@ -0,0 +1,280 @@
+package taps
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ptnDecimalFormat = regexp.MustCompile(`%[-+# 0-9]*\.([0-9]+)f`)
+
+// ---------------------------------------------
+// Check value
+// ---------------------------------------------
+// checkValue checks s against Attr, Picture and DataLen of the field.
+func (f *DataField) checkValue(s string) error {
+	name := getBaseName(f.Name)
+	if (f.Attr == "N" || f.Attr == "n") && strings.TrimSpace(s) != "" {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+			return fmt.Errorf("taps: %s: %q is not numeric", name, s)
+		}
+	}
+	data := []rune(s)
+	if len(f.Picture) > 0 {
+		data = f.pictureData(s)
+		if !f.conformsPicture(data) {
+			return fmt.Errorf("taps: %s: %q does not match picture %q", name, s, f.Picture)
+		}
+	}
+	if f.DataLen > 0 && len(data) > f.DataLen {
+		return fmt.Errorf("taps: %s: %q is longer than %d", name, s, f.DataLen)
+	}
+	return nil
+}
+
+func (p *Panel) storeValue(s string, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	if err := f.checkValue(s); err != nil {
+		return err
+	}
+	p.Store(s, n)
+	return nil
+}
+
+func (p *Panel) getValue(n string, v any) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	rv := reflect.ValueOf(v).Elem()
+	return f.parseValue(f.getValue(rv), rv)
+}
+
+// ---------------------------------------------
+// Decimal
+// ---------------------------------------------
+// getDecimalPlaces returns the decimal places from Format (e.g. "%.2f")
+// or Picture (e.g. "9999.99"), or -1.
+func (f *DataField) getDecimalPlaces() int {
+	if m := ptnDecimalFormat.FindStringSubmatch(f.Format); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	if k := strings.LastIndex(f.Picture, "."); k >= 0 {
+		n := 0
+		for _, c := range f.Picture[k+1:] {
+			if isPictureSlot(c) {
+				n++
+			}
+		}
+		return n
+	}
+	return -1
+}
+
+// getDecimalPoint returns the position of the decimal point in Picture,
+// or -1.
+func (f *DataField) getDecimalPoint() int {
+	point := -1
+	for i, c := range []rune(f.Picture) {
+		if c == '.' {
+			point = i
+		}
+	}
+	return point
+}
+
+// numberPicture fits the number s to the numeric Picture, e.g. "12.5" is
+// "0012.50" for "9999.99". s is returned as it is if it does not fit.
+func (f *DataField) numberPicture(s string) string {
+	if len(f.Picture) == 0 || strings.Trim(s, "0123456789.") != "" {
+		return s
+	}
+	pic := []rune(f.Picture)
+	point := f.getDecimalPoint()
+	intSlots, decSlots := 0, 0
+	for i, c := range pic {
+		if !isPictureSlot(c) {
+			continue
+		}
+		if c != '9' {
+			return s
+		}
+		if point >= 0 && i > point {
+			decSlots++
+		} else {
+			intSlots++
+		}
+	}
+
+	intPart, decPart, _ := strings.Cut(s, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	decPart = strings.TrimRight(decPart, "0")
+	if len(intPart) > intSlots || len(decPart) > decSlots {
+		return s
+	}
+	intPart = strings.Repeat("0", intSlots-len(intPart)) + intPart
+	decPart = decPart + strings.Repeat("0", decSlots-len(decPart))
+	return string(f.formatPicture([]rune(intPart + decPart)))
+}
+
+func (f *DataField) formatFloat(v float64, bitSize int) string {
+	if strings.Contains(f.Format, "%") {
+		return f.numberPicture(fmt.Sprintf(f.Format, v))
+	}
+	return f.numberPicture(strconv.FormatFloat(v, 'f', f.getDecimalPlaces(), bitSize))
+}
+
+func (f *DataField) formatDecimal(v *big.Rat) string {
+	if v == nil {
+		return ""
+	}
+	places := f.getDecimalPlaces()
+	if places < 0 {
+		places, _ = v.FloatPrec()
+	}
+	return f.numberPicture(v.FloatString(places))
+}
+
+func (f *DataField) parseDecimal(s string) (*big.Rat, error) {
+	n := strings.TrimSpace(s)
+	if n == "" {
+		return new(big.Rat), nil
+	}
+	v, ok := new(big.Rat).SetString(n)
+	if !ok || strings.ContainsAny(n, "/eE") {
+		return nil, fmt.Errorf("taps: %s: cannot convert %q to decimal", getBaseName(f.Name), s)
+	}
+	return v, nil
+}
+
+// ---------------------------------------------
+// Typed Get
+// ---------------------------------------------
+func (p *Panel) GetInt(n string) (int, error) {
+	var v int
+	err := p.getValue(n, &v)
+	return v, err
+}
+
+func (p *Panel) GetFloat(n string) (float64, error) {
+	var v float64
+	err := p.getValue(n, &v)
+	return v, err
+}
+
+func (p *Panel) GetDecimal(n string) (*big.Rat, error) {
+	f := p.GetDataField(n)
+	if f == nil {
+		return nil, fmt.Errorf("taps: %s: no such field", n)
+	}
+	return f.parseDecimal(f.getNumber())
+}
+
+func (p *Panel) GetBool(n string) (bool, error) {
+	var v bool
+	err := p.getValue(n, &v)
+	return v, err
+}
+
+// GetTime parses the data with Format of the field.
+func (p *Panel) GetTime(n string) (time.Time, error) {
+	var v time.Time
+	err := p.getValue(n, &v)
+	return v, err
+}
+
+func (p *Panel) GetGridInt(n string, col, row int) (int, error) {
+	return p.GetInt(p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) GetGridFloat(n string, col, row int) (float64, error) {
+	return p.GetFloat(p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) GetGridDecimal(n string, col, row int) (*big.Rat, error) {
+	return p.GetDecimal(p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) GetGridBool(n string, col, row int) (bool, error) {
+	return p.GetBool(p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) GetGridTime(n string, col, row int) (time.Time, error) {
+	return p.GetTime(p.GetGridFieldName(n, col, row))
+}
+
+// ---------------------------------------------
+// Typed Store
+// ---------------------------------------------
+// StoreInt fits v to Picture of the field, e.g. 12 is "0012" for "9999".
+func (p *Panel) StoreInt(v int, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	return p.storeValue(f.numberPicture(strconv.Itoa(v)), n)
+}
+
+// StoreFloat formats v with Format of the field, e.g. "%.2f", and fits it
+// to the integer and decimal places of Picture, e.g. "9999.99".
+func (p *Panel) StoreFloat(v float64, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	return p.storeValue(f.formatFloat(v, 64), n)
+}
+
+// StoreDecimal rounds v to the decimal places of Format or Picture.
+func (p *Panel) StoreDecimal(v *big.Rat, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	return p.storeValue(f.formatDecimal(v), n)
+}
+
+func (p *Panel) StoreBool(v bool, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	return p.storeValue(f.formatBool(v), n)
+}
+
+// StoreTime formats v with Format of the field.
+func (p *Panel) StoreTime(v time.Time, n string) error {
+	f := p.GetDataField(n)
+	if f == nil {
+		return fmt.Errorf("taps: %s: no such field", n)
+	}
+	return p.storeValue(f.formatTime(v), n)
+}
+
+func (p *Panel) StoreGridInt(v int, n string, col, row int) error {
+	return p.StoreInt(v, p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) StoreGridFloat(v float64, n string, col, row int) error {
+	return p.StoreFloat(v, p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) StoreGridDecimal(v *big.Rat, n string, col, row int) error {
+	return p.StoreDecimal(v, p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) StoreGridBool(v bool, n string, col, row int) error {
+	return p.StoreBool(v, p.GetGridFieldName(n, col, row))
+}
+
+func (p *Panel) StoreGridTime(v time.Time, n string, col, row int) error {
+	return p.StoreTime(v, p.GetGridFieldName(n, col, row))
+}
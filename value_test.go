@@ -0,0 +1,51 @@
+package taps
+
+import (
+	"math/big"
+	"testing"
+)
+
+const valueDoc = `
+[[Field]]
+Name = "AMOUNT"
+X = 1
+Y = 1
+FieldLen = 10
+Style = "edit, edit_focus"
+FieldType = "edit"
+Picture = "9,999.99"
+`
+
+func TestDecimalPicture(t *testing.T) {
+	newTestScreen(t)
+	p := NewPanel(valueDoc, testStyleMatrix, "")
+
+	p.Store("1,234.56", "AMOUNT")
+	if v, err := p.GetFloat("AMOUNT"); err != nil || v != 1234.56 {
+		t.Errorf("GetFloat = %v, %v, want 1234.56", v, err)
+	}
+	if v, err := p.GetDecimal("AMOUNT"); err != nil || v.Cmp(big.NewRat(123456, 100)) != 0 {
+		t.Errorf("GetDecimal = %v, %v, want 1234.56", v, err)
+	}
+
+	if err := p.StoreFloat(12.5, "AMOUNT"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Get("AMOUNT"); got != "0,012.50" {
+		t.Errorf("StoreFloat(12.5) = %q, want 0,012.50", got)
+	}
+	if v, err := p.GetFloat("AMOUNT"); err != nil || v != 12.5 {
+		t.Errorf("GetFloat = %v, %v, want 12.5", v, err)
+	}
+
+	if err := p.StoreDecimal(big.NewRat(7, 4), "AMOUNT"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Get("AMOUNT"); got != "0,001.75" {
+		t.Errorf("StoreDecimal(7/4) = %q, want 0,001.75", got)
+	}
+
+	if err := p.StoreFloat(12345, "AMOUNT"); err == nil {
+		t.Error("StoreFloat(12345) fits 9,999.99")
+	}
+}
@@ -0,0 +1,119 @@
+package taps
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"unicode"
+)
+
+// ---------------------------------------------
+// Word
+// ---------------------------------------------
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// nextWordPos returns the end of the word at or after pos.
+func nextWordPos(data []rune, pos int) int {
+	for pos < len(data) && !isWordRune(data[pos]) {
+		pos++
+	}
+	for pos < len(data) && isWordRune(data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// priorWordPos returns the start of the word before pos.
+func priorWordPos(data []rune, pos int) int {
+	for pos > 0 && !isWordRune(data[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(data[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// doWord does the word motion and the word deletion in edit fields.
+//
+//	Alt-F, Ctrl-Right  the end of the word
+//	Alt-B, Ctrl-Left   the start of the word
+//	Alt-D              kill the word after the cursor
+//	Ctrl-W             kill the word before the cursor
+//	Ctrl-U             kill the text before the cursor
+func (p *Panel) doWord(i int, cKey tcell.Key, rKey rune, mod tcell.ModMask) bool {
+	f := p.Field[i]
+	alt := cKey == tcell.KeyRune && mod&tcell.ModAlt != 0
+	switch {
+	case (alt && (rKey == 'f' || rKey == 'F')) || (cKey == tcell.KeyRight && mod&tcell.ModCtrl != 0):
+		p.moveTo(i, nextWordPos(f.RData, f.hDataPos))
+	case (alt && (rKey == 'b' || rKey == 'B')) || (cKey == tcell.KeyLeft && mod&tcell.ModCtrl != 0):
+		p.moveTo(i, priorWordPos(f.RData, f.hDataPos))
+	case alt && (rKey == 'd' || rKey == 'D'):
+		p.killTo(i, nextWordPos(f.RData, f.hDataPos))
+	case cKey == tcell.KeyCtrlW:
+		p.killTo(i, priorWordPos(f.RData, f.hDataPos))
+	case cKey == tcell.KeyCtrlU:
+		p.killTo(i, 0)
+	default:
+		return false
+	}
+	return true
+}
+
+// moveTo moves the cursor to pos by input_rt and input_lt, so that the
+// wrapped lines of list fields and the pictures are followed.
+func (p *Panel) moveTo(i, pos int) {
+	f := p.Field[i]
+	for f.hDataPos < pos {
+		x := f.hDataPos
+		p.input_rt(i)
+		if f.hDataPos == x {
+			break
+		}
+	}
+	for f.hDataPos > pos {
+		x := f.hDataPos
+		p.input_lt(i)
+		if f.hDataPos == x {
+			break
+		}
+	}
+}
+
+// killTo deletes the text between the cursor and pos, and pushes it to
+// the kill ring.
+func (p *Panel) killTo(i, pos int) {
+	f := p.Field[i]
+	if pos == f.hDataPos {
+		return
+	}
+	u := p.getUndoState(i)
+	if pos > f.hDataPos {
+		pushKill(string(f.RData[f.hDataPos:pos]))
+	} else {
+		pushKill(string(f.RData[pos:f.hDataPos]))
+	}
+	p.deleteTo(i, pos)
+	p.pushUndo(u, tcell.KeyCtrlW)
+}
+
+// deleteTo deletes the text between the cursor and pos by input_del and
+// input_bs. The literals of Picture are stepped over, as input_bs does.
+func (p *Panel) deleteTo(i, pos int) {
+	f := p.Field[i]
+	for n := f.editableCount(f.hDataPos, pos); n > 0; n-- {
+		x := len(f.RData)
+		p.input_del(i)
+		if len(f.RData) == x {
+			break
+		}
+	}
+	for n := f.editableCount(pos, f.hDataPos); n > 0; n-- {
+		x := f.hDataPos
+		p.input_bs(i)
+		if f.hDataPos == x {
+			break
+		}
+	}
+}